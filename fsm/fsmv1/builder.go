@@ -0,0 +1,127 @@
+package fsmv1
+
+import (
+	"fmt"
+	"time"
+)
+
+// Builder 链式定义状态机, 例:
+//
+//	var err = m.Define("order").
+//		Initial("created").
+//		Final("shipped", "closed").
+//		On("created", "pay", payHandler, "paid", "closed").
+//		Guard("created", "pay", "stock", stockGuard).
+//		On("paid", "ship", shipHandler, "shipped").
+//		OnEnter("shipped", notify).
+//		Build()
+type Builder struct {
+	m      *Machine
+	scheme Scheme
+
+	ops []func(sc *scheme) error
+}
+
+func (b *Builder) op(fn func(sc *scheme) error) *Builder {
+	b.ops = append(b.ops, fn)
+	return b
+}
+
+// On 定义 state 下 event 的处理方法, {to} 为允许的目标状态, 为空时不限制
+func (b *Builder) On(state State, event Event, handler Handler, to ...State) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.AddTransition(state, event, wrapHandler(handler), to...)
+	})
+}
+
+// Guard 为 state 下的 event 添加守卫方法, 需在对应的 On 之后调用
+func (b *Builder) Guard(state State, event Event, name string, g Guard) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.AddGuard(state, event, name, wrapGuard(g))
+	})
+}
+
+// OnEnter 添加进入 state 时的回调
+func (b *Builder) OnEnter(state State, hook StateHook) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.OnEnter(state, wrapStateHook(hook))
+	})
+}
+
+// OnExit 添加离开 state 时的回调
+func (b *Builder) OnExit(state State, hook StateHook) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.OnExit(state, wrapStateHook(hook))
+	})
+}
+
+// After 添加状态转换成功后的回调
+func (b *Builder) After(hook AfterHook) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.AfterTransition(wrapAfterHook(b.scheme, hook))
+	})
+}
+
+// States 显式声明状态
+func (b *Builder) States(states ...State) *Builder {
+	return b.op(func(sc *scheme) error {
+		sc.AddStates(states...)
+		return nil
+	})
+}
+
+// Initial 设置初始状态
+func (b *Builder) Initial(state State) *Builder {
+	return b.op(func(sc *scheme) error {
+		sc.SetInitial(state)
+		return nil
+	})
+}
+
+// Final 声明终止状态
+func (b *Builder) Final(states ...State) *Builder {
+	return b.op(func(sc *scheme) error {
+		sc.SetFinal(states...)
+		return nil
+	})
+}
+
+// Parent 设置 child 的父状态
+func (b *Builder) Parent(child State, parent State) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.SetParent(child, parent)
+	})
+}
+
+// OnAny 定义任意状态下 event 的处理方法
+func (b *Builder) OnAny(event Event, handler Handler, to ...State) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.AddAnyTransition(event, wrapHandler(handler), to...)
+	})
+}
+
+// Timeout 实例在 state 停留超过 d 后自动触发 event
+func (b *Builder) Timeout(state State, d time.Duration, event Event) *Builder {
+	return b.op(func(sc *scheme) error {
+		return sc.SetTimeout(state, d, event)
+	})
+}
+
+// Build 注册状态机及全部定义, 任意一步出错则不做任何修改
+func (b *Builder) Build() error {
+	var sc = NewTypedMachine[State, Event, []interface{}](b.scheme)
+	for _, op := range b.ops {
+		if err := op(sc); err != nil {
+			return err
+		}
+	}
+
+	b.m.mux.Lock()
+	defer b.m.mux.Unlock()
+
+	if _, ok := b.m.schemes[b.scheme]; ok {
+		return fmt.Errorf("scheme (%s) has registed", b.scheme)
+	}
+	b.m.schemes[b.scheme] = sc
+	return nil
+}
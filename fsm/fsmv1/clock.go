@@ -0,0 +1,132 @@
+package fsmv1
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 时钟, 测试中可替换为 FakeClock
+type Clock interface {
+	Now() time.Time
+	// AfterFunc 在 d 之后调用 f
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer 由 Clock.AfterFunc 创建的定时器
+type Timer interface {
+	// Stop 取消定时器, 定时器已触发或已取消时返回 false
+	Stop() bool
+}
+
+// SystemClock 系统时钟
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// FakeClock 手动推进的时钟, 用于测试, 并发安全
+type FakeClock struct {
+	mux sync.Mutex
+
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	c    *FakeClock
+	at   time.Time
+	f    func()
+	done bool // 已触发或已取消
+}
+
+// NewFakeClock 初始化
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now: now,
+	}
+}
+
+// Now 当前时间
+func (c *FakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.now
+}
+
+// AfterFunc 在 Advance 推进到 Now()+d 时调用 f
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	var t = &fakeTimer{
+		c:  c,
+		at: c.now.Add(d),
+		f:  f,
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance 推进时间, 按到期顺序在当前 goroutine 中同步调用到期的定时器
+// 定时器回调中新建的定时器若在推进范围内到期, 同样会被调用
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mux.Lock()
+	var target = c.now.Add(d)
+
+	for {
+		var next = c.due(target)
+		if next == nil {
+			break
+		}
+
+		next.done = true
+		c.now = next.at
+
+		c.mux.Unlock()
+		next.f()
+		c.mux.Lock()
+	}
+
+	c.now = target
+	c.mux.Unlock()
+}
+
+// due 返回最早到期的定时器, 并移除已完成的定时器, 调用方需持有锁
+func (c *FakeClock) due(target time.Time) *fakeTimer {
+	var alive = c.timers[:0]
+	for _, t := range c.timers {
+		if !t.done {
+			alive = append(alive, t)
+		}
+	}
+	c.timers = alive
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+
+	if len(c.timers) == 0 || c.timers[0].at.After(target) {
+		return nil
+	}
+	return c.timers[0]
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.mux.Lock()
+	defer t.c.mux.Unlock()
+
+	if t.done {
+		return false
+	}
+	t.done = true
+	return true
+}
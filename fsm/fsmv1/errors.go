@@ -0,0 +1,49 @@
+package fsmv1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypedTransitionError 处理方法返回了未声明的目标状态
+type TypedTransitionError[S comparable, E comparable] struct {
+	Scheme  Scheme
+	From    S
+	Event   E
+	To      S   // 处理方法返回的状态
+	Allowed []S // 声明允许的目标状态
+}
+
+func (e *TypedTransitionError[S, E]) Error() string {
+	var allowed = make([]string, len(e.Allowed))
+	for i, s := range e.Allowed {
+		allowed[i] = fmt.Sprint(s)
+	}
+
+	return fmt.Sprintf("scheme (%s) state(%v) event(%v) illegal transition to state(%v), allowed [%s]",
+		e.Scheme, e.From, e.Event, e.To, strings.Join(allowed, ", "))
+}
+
+// TypedGuardError 事件被守卫方法拒绝
+type TypedGuardError[S comparable, E comparable] struct {
+	Scheme Scheme
+	State  S
+	Event  E
+	Guard  string // 守卫名称
+	Err    error  // 守卫返回的错误
+}
+
+func (e *TypedGuardError[S, E]) Error() string {
+	return fmt.Sprintf("scheme (%s) state(%v) event(%v) rejected by guard(%s): %v",
+		e.Scheme, e.State, e.Event, e.Guard, e.Err)
+}
+
+func (e *TypedGuardError[S, E]) Unwrap() error {
+	return e.Err
+}
+
+// TransitionError 字符串版本的 TypedTransitionError
+type TransitionError = TypedTransitionError[State, Event]
+
+// GuardError 字符串版本的 TypedGuardError
+type GuardError = TypedGuardError[State, Event]
@@ -0,0 +1,238 @@
+package fsmv1
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Edge 某状态下某事件的定义快照, 用于导出和检查
+type Edge[S comparable, E comparable] struct {
+	Any    bool // 任意状态下的事件, 此时 From 为零值
+	From   S
+	Event  E
+	To     []S      // 声明允许的目标状态, 为空时不限制
+	Guards []string // 守卫名称
+}
+
+// Edges 返回全部状态转换定义, 按 From, Event 的字符串形式排序, 任意状态的事件排在最后
+func (m *TypedMachine[S, E, P]) Edges() []Edge[S, E] {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var edges []Edge[S, E]
+	for from, eh := range m.table {
+		for event, tr := range eh {
+			var guards = make([]string, len(tr.guards))
+			for i, g := range tr.guards {
+				guards[i] = g.name
+			}
+
+			edges = append(edges, Edge[S, E]{
+				From:   from,
+				Event:  event,
+				To:     append([]S(nil), tr.to...),
+				Guards: guards,
+			})
+		}
+	}
+
+	for event, tr := range m.any {
+		var guards = make([]string, len(tr.guards))
+		for i, g := range tr.guards {
+			guards[i] = g.name
+		}
+
+		edges = append(edges, Edge[S, E]{
+			Any:    true,
+			Event:  event,
+			To:     append([]S(nil), tr.to...),
+			Guards: guards,
+		})
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Any != edges[j].Any {
+			return !edges[i].Any
+		}
+
+		var fi, fj = fmt.Sprint(edges[i].From), fmt.Sprint(edges[j].From)
+		if fi != fj {
+			return fi < fj
+		}
+		return fmt.Sprint(edges[i].Event) < fmt.Sprint(edges[j].Event)
+	})
+	return edges
+}
+
+// label 边的标签: event [guard1, guard2]
+func label[S comparable, E comparable](e Edge[S, E]) string {
+	if len(e.Guards) == 0 {
+		return fmt.Sprint(e.Event)
+	}
+	return fmt.Sprintf("%v [%s]", e.Event, strings.Join(e.Guards, ", "))
+}
+
+// quoteDOT 转义为 DOT 的双引号字符串
+func quoteDOT(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// DOT 导出为 Graphviz DOT 格式
+// 初始状态由实心点指向, 终止状态为双线框, 未声明目标状态的事件指向虚线节点 "?",
+// 任意状态的事件从节点 "*" 出发, 子状态以点线空心箭头指向父状态
+func (m *TypedMachine[S, E, P]) DOT() string {
+	var edges = m.Edges()
+	var initial, hasInitial = m.Initial()
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf("digraph %s {\n", quoteDOT(string(m.name))))
+	buf.WriteString("\trankdir=LR;\n")
+	buf.WriteString("\tnode [shape=box, style=rounded];\n")
+
+	for _, s := range m.States() {
+		if m.IsFinal(s) {
+			buf.WriteString(fmt.Sprintf("\t%s [peripheries=2];\n", quoteDOT(fmt.Sprint(s))))
+			continue
+		}
+		buf.WriteString(fmt.Sprintf("\t%s;\n", quoteDOT(fmt.Sprint(s))))
+	}
+
+	if hasInitial {
+		buf.WriteString("\t\"__start\" [shape=point, label=\"\"];\n")
+		buf.WriteString(fmt.Sprintf("\t\"__start\" -> %s;\n", quoteDOT(fmt.Sprint(initial))))
+	}
+
+	var unknown, anyState bool
+	for _, e := range edges {
+		var from = quoteDOT(fmt.Sprint(e.From))
+		var lbl = quoteDOT(label(e))
+		if e.Any {
+			from, anyState = `"*"`, true
+		}
+
+		if len(e.To) == 0 {
+			unknown = true
+			buf.WriteString(fmt.Sprintf("\t%s -> \"?\" [label=%s, style=dashed];\n", from, lbl))
+			continue
+		}
+
+		for _, to := range e.To {
+			buf.WriteString(fmt.Sprintf("\t%s -> %s [label=%s];\n", from, quoteDOT(fmt.Sprint(to)), lbl))
+		}
+	}
+
+	if unknown {
+		buf.WriteString("\t\"?\" [shape=plaintext];\n")
+	}
+	if anyState {
+		buf.WriteString("\t\"*\" [shape=plaintext];\n")
+	}
+
+	for _, s := range m.States() {
+		if p, ok := m.Parent(s); ok {
+			buf.WriteString(fmt.Sprintf("\t%s -> %s [style=dotted, arrowhead=empty];\n", quoteDOT(fmt.Sprint(s)), quoteDOT(fmt.Sprint(p))))
+		}
+	}
+
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// Mermaid 导出为 Mermaid stateDiagram-v2 格式
+// 状态以 s0, s1... 作为标识, 初始/终止状态与 [*] 相连, 未声明目标状态的事件指向状态 "?",
+// 任意状态的事件从状态 "*" 出发; 父子关系不体现在图中
+func (m *TypedMachine[S, E, P]) Mermaid() string {
+	var edges = m.Edges()
+	var initial, hasInitial = m.Initial()
+	var buf bytes.Buffer
+
+	buf.WriteString("stateDiagram-v2\n")
+
+	var ids = make(map[S]string)
+	var list = m.States()
+	for i, s := range list {
+		ids[s] = fmt.Sprintf("s%d", i)
+		buf.WriteString(fmt.Sprintf("\tstate %s as %s\n", quoteMermaid(fmt.Sprint(s)), ids[s]))
+	}
+
+	if hasInitial {
+		buf.WriteString(fmt.Sprintf("\t[*] --> %s\n", ids[initial]))
+	}
+
+	var unknown, anyState bool
+	for _, e := range edges {
+		var from = ids[e.From]
+		if e.Any {
+			if !anyState {
+				anyState = true
+				buf.WriteString("\tstate \"*\" as any\n")
+			}
+			from = "any"
+		}
+
+		if len(e.To) == 0 {
+			if !unknown {
+				unknown = true
+				buf.WriteString("\tstate \"?\" as unknown\n")
+			}
+			buf.WriteString(fmt.Sprintf("\t%s --> unknown : %s\n", from, escapeMermaid(label(e))))
+			continue
+		}
+
+		for _, to := range e.To {
+			buf.WriteString(fmt.Sprintf("\t%s --> %s : %s\n", from, ids[to], escapeMermaid(label(e))))
+		}
+	}
+
+	for _, s := range list {
+		if m.IsFinal(s) {
+			buf.WriteString(fmt.Sprintf("\t%s --> [*]\n", ids[s]))
+		}
+	}
+
+	return buf.String()
+}
+
+// quoteMermaid 转义为 Mermaid 的状态描述
+func quoteMermaid(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#34;") + `"`
+}
+
+// escapeMermaid 转义 Mermaid 边标签中的特殊字符
+func escapeMermaid(s string) string {
+	s = strings.ReplaceAll(s, ":", "#58;")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
+
+// DOT 导出 scheme 为 Graphviz DOT 格式
+func (m *Machine) DOT(s Scheme) (string, error) {
+	var sc, err = m.lookup(s)
+	if err != nil {
+		return "", err
+	}
+	return sc.DOT(), nil
+}
+
+// Mermaid 导出 scheme 为 Mermaid stateDiagram-v2 格式
+func (m *Machine) Mermaid(s Scheme) (string, error) {
+	var sc, err = m.lookup(s)
+	if err != nil {
+		return "", err
+	}
+	return sc.Mermaid(), nil
+}
+
+// DOT 导出 scheme 为 Graphviz DOT 格式 (默认Machine)
+func DOT(s Scheme) (string, error) {
+	return defaultMachine.DOT(s)
+}
+
+// Mermaid 导出 scheme 为 Mermaid stateDiagram-v2 格式 (默认Machine)
+func Mermaid(s Scheme) (string, error) {
+	return defaultMachine.Mermaid(s)
+}
@@ -0,0 +1,115 @@
+package fsmv1_test
+
+import (
+	"testing"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+func exportMachine(t *testing.T) *fsmv1.Machine {
+	var m = fsmv1.NewMachine()
+	var ok = func(...interface{}) error { return nil }
+
+	var err = m.Define("order").
+		On("created", "pay", to("paid"), "paid", "closed").
+		Guard("created", "pay", "stock", ok).
+		Guard("created", "pay", "balance", ok).
+		On("paid", "ship", to("shipped"), "shipped").
+		On("shipped", "note", to("shipped")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestDOT(t *testing.T) {
+	var out, err = exportMachine(t).DOT("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expect = `digraph "order" {
+	rankdir=LR;
+	node [shape=box, style=rounded];
+	"closed";
+	"created";
+	"paid";
+	"shipped";
+	"created" -> "paid" [label="pay [stock, balance]"];
+	"created" -> "closed" [label="pay [stock, balance]"];
+	"paid" -> "shipped" [label="ship"];
+	"shipped" -> "?" [label="note", style=dashed];
+	"?" [shape=plaintext];
+}
+`
+	if out != expect {
+		t.Fatalf("got:\n%s\nexpect:\n%s", out, expect)
+	}
+
+	if _, err := fsmv1.DOT("not-exist"); err == nil {
+		t.Fatal("expect unregistered scheme error")
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	var out, err = exportMachine(t).Mermaid("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expect = `stateDiagram-v2
+	state "closed" as s0
+	state "created" as s1
+	state "paid" as s2
+	state "shipped" as s3
+	s1 --> s2 : pay [stock, balance]
+	s1 --> s0 : pay [stock, balance]
+	s2 --> s3 : ship
+	state "?" as unknown
+	s3 --> unknown : note
+`
+	if out != expect {
+		t.Fatalf("got:\n%s\nexpect:\n%s", out, expect)
+	}
+}
+
+func TestMermaidInitialFinal(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	var err = m.Define("ticket").
+		Initial("open").
+		Final("closed").
+		On("open", "close", to("closed"), "closed").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out, _ = m.Mermaid("ticket")
+	var expect = `stateDiagram-v2
+	state "closed" as s0
+	state "open" as s1
+	[*] --> s1
+	s1 --> s0 : close
+	s0 --> [*]
+`
+	if out != expect {
+		t.Fatalf("got:\n%s\nexpect:\n%s", out, expect)
+	}
+
+	out, _ = m.DOT("ticket")
+	expect = `digraph "ticket" {
+	rankdir=LR;
+	node [shape=box, style=rounded];
+	"closed" [peripheries=2];
+	"open";
+	"__start" [shape=point, label=""];
+	"__start" -> "open";
+	"open" -> "closed" [label="close"];
+}
+`
+	if out != expect {
+		t.Fatalf("got:\n%s\nexpect:\n%s", out, expect)
+	}
+}
@@ -1,12 +1,16 @@
 package fsmv1
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"sync"
+)
 
 // 简易版状态机工具
 
 var (
-	// m is a map from scheme to resolver builder.
-	m = make(map[Scheme]map[State]map[Event]Handler)
+	// defaultMachine 包级函数使用的默认状态机
+	defaultMachine = NewMachine()
 )
 
 // Scheme 状态机
@@ -21,32 +25,77 @@ type Event string
 // Handler 处理方法，返回新的状态
 type Handler func(v ...interface{}) (State, error)
 
+// Machine 状态机集合, 每个实例拥有独立的 scheme -> state -> event -> handler 表, 并发安全
+type Machine struct {
+	mux sync.RWMutex
+
+	schemes map[Scheme]*scheme
+}
+
+// scheme 单个字符串版本的状态机, 处理方法参数为 Do 的可变参数
+type scheme = TypedMachine[State, Event, []interface{}]
+
+func wrapHandler(h Handler) TypedHandler[State, []interface{}] {
+	if h == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, v []interface{}) (State, error) {
+		return h(v...)
+	}
+}
+
+// NewMachine 初始化
+func NewMachine() *Machine {
+	return &Machine{
+		schemes: make(map[Scheme]*scheme),
+	}
+}
+
 // Register 注册状态机
-func Register(s Scheme) error {
-	if _, ok := m[s]; ok {
+func (m *Machine) Register(s Scheme) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if _, ok := m.schemes[s]; ok {
 		return fmt.Errorf("scheme (%s) has registed", s)
 	}
-	m[s] = make(map[State]map[Event]Handler)
+	m.schemes[s] = NewTypedMachine[State, Event, []interface{}](s)
 	return nil
 }
 
-// AddHandler 添加处理事件
-func AddHandler(s Scheme, state State, event Event, handler Handler) error {
-	var m, ok = m[s]
+func (m *Machine) lookup(s Scheme) (*scheme, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var sc, ok = m.schemes[s]
 	if !ok {
-		return fmt.Errorf("scheme (%s) has not regist", s)
+		return nil, fmt.Errorf("scheme (%s) has not regist", s)
 	}
+	return sc, nil
+}
 
-	if _, ok := m[state]; !ok {
-		m[state] = make(map[Event]Handler)
+// update 修改已注册的状态机
+func (m *Machine) update(s Scheme, fn func(sc *scheme) error) error {
+	var sc, err = m.lookup(s)
+	if err != nil {
+		return err
 	}
 
-	if _, ok := m[state][event]; ok {
-		return fmt.Errorf("state(%s) event(%s) has defined", state, event)
-	}
+	return fn(sc)
+}
 
-	m[state][event] = handler
-	return nil
+// AddHandler 添加处理事件, 不限制处理方法返回的目标状态
+func (m *Machine) AddHandler(s Scheme, state State, event Event, handler Handler) error {
+	return m.AddTransition(s, state, event, handler)
+}
+
+// AddTransition 添加处理事件, 并声明允许的目标状态
+// 处理方法返回 {to} 以外的状态时, Do 返回 *TransitionError
+func (m *Machine) AddTransition(s Scheme, state State, event Event, handler Handler, to ...State) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.AddTransition(state, event, wrapHandler(handler), to...)
+	})
 }
 
 // Do 状态转换
@@ -54,24 +103,79 @@ func AddHandler(s Scheme, state State, event Event, handler Handler) error {
 // 		@scheme 状态机名称
 // 		@state 当前状态
 //		@event 可处理事件
-//      @v 自定义可变参数
+//      @v 自定义可变参数, 原样传递给 handler/guard/hook
 // @return
 //		@state 转换后的状态
-func Do(scheme Scheme, state State, event Event, v ...interface{}) (State, error) {
-	var m, ok = m[scheme]
-	if !ok {
-		return "", fmt.Errorf("scheme (%s) has not regist", scheme)
-	}
+//
+// 调用顺序及错误处理见 TypedMachine.Do; 与旧版一致, handler 返回错误时返回 handler 返回的状态
+func (m *Machine) Do(scheme Scheme, state State, event Event, v ...interface{}) (State, error) {
+	return m.DoContext(context.Background(), scheme, state, event, v...)
+}
 
-	var eh, ok1 = m[state]
-	if !ok1 {
-		return "", fmt.Errorf("state(%s) has not define event and handler", state)
+// DoContext 状态转换, ctx 结束时终止并返回 ctx.Err()
+func (m *Machine) DoContext(ctx context.Context, scheme Scheme, state State, event Event, v ...interface{}) (State, error) {
+	var sc, err = m.lookup(scheme)
+	if err != nil {
+		return "", err
 	}
 
-	var fn, ok2 = eh[event]
-	if !ok2 {
-		return "", fmt.Errorf("event(%s) has no handlers", event)
+	return sc.do(ctx, state, event, v)
+}
+
+// Define 链式定义状态机, 调用 Build 后注册到当前 Machine
+func (m *Machine) Define(s Scheme) *Builder {
+	return &Builder{
+		m:      m,
+		scheme: s,
 	}
+}
+
+// Register 注册状态机 (默认Machine)
+func Register(s Scheme) error {
+	return defaultMachine.Register(s)
+}
+
+// AddHandler 添加处理事件 (默认Machine)
+func AddHandler(s Scheme, state State, event Event, handler Handler) error {
+	return defaultMachine.AddHandler(s, state, event, handler)
+}
+
+// AddTransition 添加处理事件, 并声明允许的目标状态 (默认Machine)
+func AddTransition(s Scheme, state State, event Event, handler Handler, to ...State) error {
+	return defaultMachine.AddTransition(s, state, event, handler, to...)
+}
+
+// AddGuard 为 state 下的 event 添加守卫方法 (默认Machine)
+func AddGuard(s Scheme, state State, event Event, name string, g Guard) error {
+	return defaultMachine.AddGuard(s, state, event, name, g)
+}
+
+// OnEnter 添加进入 state 时的回调 (默认Machine)
+func OnEnter(s Scheme, state State, hook StateHook) error {
+	return defaultMachine.OnEnter(s, state, hook)
+}
+
+// OnExit 添加离开 state 时的回调 (默认Machine)
+func OnExit(s Scheme, state State, hook StateHook) error {
+	return defaultMachine.OnExit(s, state, hook)
+}
+
+// AfterTransition 添加状态转换完成后的回调 (默认Machine)
+func AfterTransition(s Scheme, hook AfterHook) error {
+	return defaultMachine.AfterTransition(s, hook)
+}
+
+// Do 状态转换 (默认Machine)
+func Do(scheme Scheme, state State, event Event, v ...interface{}) (State, error) {
+	return defaultMachine.Do(scheme, state, event, v...)
+}
+
+// DoContext 状态转换 (默认Machine)
+func DoContext(ctx context.Context, scheme Scheme, state State, event Event, v ...interface{}) (State, error) {
+	return defaultMachine.DoContext(ctx, scheme, state, event, v...)
+}
 
-	return fn(v)
+// Define 链式定义状态机 (默认Machine)
+func Define(s Scheme) *Builder {
+	return defaultMachine.Define(s)
 }
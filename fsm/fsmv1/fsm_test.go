@@ -1,9 +1,159 @@
 package fsmv1_test
 
 import (
+	"errors"
+	"sync"
 	"testing"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
 )
 
+func to(s fsmv1.State) fsmv1.Handler {
+	return func(v ...interface{}) (fsmv1.State, error) {
+		return s, nil
+	}
+}
+
 func TestFsm(t *testing.T) {
+	if err := fsmv1.Register("test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsmv1.Register("test"); err == nil {
+		t.Fatal("expect duplicate register error")
+	}
+	if err := fsmv1.AddHandler("test", "a", "go", to("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	var s, err = fsmv1.Do("test", "a", "go")
+	if err != nil || s != "b" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+
+	if _, err := fsmv1.Do("test", "b", "go"); err == nil {
+		t.Fatal("expect undefined state error")
+	}
+	if _, err := fsmv1.Do("test", "a", "back"); err == nil {
+		t.Fatal("expect undefined event error")
+	}
+
+	// 与旧版一致, handler 返回错误时返回 handler 返回的状态
+	var errFail = errors.New("fail")
+	if err := fsmv1.AddHandler("test", "a", "fail", func(v ...interface{}) (fsmv1.State, error) {
+		return "failed", errFail
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := fsmv1.Do("test", "a", "fail"); !errors.Is(err, errFail) || s != "failed" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+}
+
+func TestMachineIsolation(t *testing.T) {
+	var m1 = fsmv1.NewMachine()
+	var m2 = fsmv1.NewMachine()
+
+	if err := m1.Register("order"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Register("order"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m1.AddHandler("order", "created", "pay", to("paid")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m2.Do("order", "created", "pay"); err == nil {
+		t.Fatal("handler leaked into another machine")
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	var err = m.Define("order").
+		On("created", "pay", to("paid")).
+		On("paid", "ship", to("shipped")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s, _ = m.Do("order", "created", "pay")
+	s, err = m.Do("order", s, "ship")
+	if err != nil || s != "shipped" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+
+	err = m.Define("dup").
+		On("a", "x", to("b")).
+		On("a", "x", to("c")).
+		Build()
+	if err == nil {
+		t.Fatal("expect duplicate definition error")
+	}
+	if _, err := m.Do("dup", "a", "x"); err == nil {
+		t.Fatal("failed build must not register scheme")
+	}
+}
+
+func TestMachineConcurrent(t *testing.T) {
+	var m = fsmv1.NewMachine()
+	if err := m.Register("c"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		var e = fsmv1.Event(string(rune('a' + i)))
+		go func() {
+			defer wg.Done()
+			var _ = m.AddHandler("c", "s", e, to("t"))
+		}()
+		go func() {
+			defer wg.Done()
+			var _, _ = m.Do("c", "s", e)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTransitionAllowed(t *testing.T) {
+	var m = fsmv1.NewMachine()
+	var next fsmv1.State
+
+	var err = m.Define("order").
+		On("created", "pay", func(v ...interface{}) (fsmv1.State, error) {
+			return next, nil
+		}, "paid", "closed").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next = "paid"
+	var s fsmv1.State
+	if s, err = m.Do("order", "created", "pay"); err != nil || s != "paid" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+
+	next = "refunded"
+	s, err = m.Do("order", "created", "pay")
+	var te *fsmv1.TransitionError
+	if !errors.As(err, &te) {
+		t.Fatalf("expect TransitionError, got %s, %v", s, err)
+	}
+	if te.From != "created" || te.To != "refunded" || len(te.Allowed) != 2 {
+		t.Fatalf("unexpected error detail: %+v", te)
+	}
 
+	if err := m.AddTransition("order", "paid", "refund", to("created"), "refunded"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Do("order", "paid", "refund"); !errors.As(err, &te) {
+		t.Fatalf("expect TransitionError, got %v", err)
+	}
 }
@@ -0,0 +1,147 @@
+package fsmv1
+
+import "fmt"
+
+// SetParent 设置 child 的父状态, child 继承父状态(及其祖先)的事件处理
+// Do 查找顺序: 当前状态 -> 父状态 -> ... -> 根状态 -> 任意状态(AddAnyTransition)
+func (m *TypedMachine[S, E, P]) SetParent(child S, parent S) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if child == parent {
+		return fmt.Errorf("state(%v) can not be parent of itself", child)
+	}
+
+	if p, ok := m.parent[child]; ok {
+		return fmt.Errorf("state(%v) has parent state(%v)", child, p)
+	}
+
+	for _, s := range m.ancestors(parent) {
+		if s == child {
+			return fmt.Errorf("state(%v) parent state(%v) cause cycle", child, parent)
+		}
+	}
+
+	m.parent[child] = parent
+	return nil
+}
+
+// Parent 返回父状态, 没有父状态时 ok 为 false
+func (m *TypedMachine[S, E, P]) Parent(state S) (parent S, ok bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	parent, ok = m.parent[state]
+	return parent, ok
+}
+
+// AddAnyTransition 添加任意状态下都可处理的事件, 如 cancel, timeout
+// 仅在当前状态及其祖先都未定义该事件时使用
+func (m *TypedMachine[S, E, P]) AddAnyTransition(event E, handler TypedHandler[S, P], to ...S) error {
+	if handler == nil {
+		return fmt.Errorf("any state event(%v) handler is nil", event)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if _, ok := m.any[event]; ok {
+		return fmt.Errorf("any state event(%v) has defined", event)
+	}
+
+	m.any[event] = &typedTransition[S, P]{
+		handler: handler,
+		to:      append([]S(nil), to...),
+	}
+	return nil
+}
+
+// AddAnyGuard 为任意状态下的 event 添加守卫方法, 需先调用 AddAnyTransition
+func (m *TypedMachine[S, E, P]) AddAnyGuard(event E, name string, g TypedGuard[P]) error {
+	if g == nil {
+		return fmt.Errorf("any state event(%v) guard(%s) is nil", event, name)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var tr, ok = m.any[event]
+	if !ok {
+		return fmt.Errorf("any state event(%v) has no handlers", event)
+	}
+
+	tr.guards = append(tr.guards[:len(tr.guards):len(tr.guards)], typedGuard[P]{name: name, fn: g})
+	return nil
+}
+
+// ancestors 返回 [state, 父状态, ..., 根状态], 调用方需持有锁
+func (m *TypedMachine[S, E, P]) ancestors(state S) []S {
+	var chain = []S{state}
+
+	for {
+		var p, ok = m.parent[state]
+		if !ok {
+			return chain
+		}
+
+		chain = append(chain, p)
+		state = p
+	}
+}
+
+// lookup 按 当前状态 -> 祖先 -> 任意状态 的顺序查找事件定义, 调用方需持有锁
+func (m *TypedMachine[S, E, P]) lookup(state S, event E) (*typedTransition[S, P], bool) {
+	for _, s := range m.ancestors(state) {
+		if tr, ok := m.table[s][event]; ok {
+			return tr, true
+		}
+	}
+
+	var tr, ok = m.any[event]
+	return tr, ok
+}
+
+// hasEvents 状态(含继承及任意状态)是否定义了事件, 调用方需持有锁
+func (m *TypedMachine[S, E, P]) hasEvents(state S) bool {
+	if len(m.any) > 0 {
+		return true
+	}
+
+	for _, s := range m.ancestors(state) {
+		if len(m.table[s]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SetParent 设置 child 的父状态
+func (m *Machine) SetParent(s Scheme, child State, parent State) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.SetParent(child, parent)
+	})
+}
+
+// AddAnyTransition 添加任意状态下都可处理的事件
+func (m *Machine) AddAnyTransition(s Scheme, event Event, handler Handler, to ...State) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.AddAnyTransition(event, wrapHandler(handler), to...)
+	})
+}
+
+// AddAnyGuard 为任意状态下的 event 添加守卫方法
+func (m *Machine) AddAnyGuard(s Scheme, event Event, name string, g Guard) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.AddAnyGuard(event, name, wrapGuard(g))
+	})
+}
+
+// SetParent 设置 child 的父状态 (默认Machine)
+func SetParent(s Scheme, child State, parent State) error {
+	return defaultMachine.SetParent(s, child, parent)
+}
+
+// AddAnyTransition 添加任意状态下都可处理的事件 (默认Machine)
+func AddAnyTransition(s Scheme, event Event, handler Handler, to ...State) error {
+	return defaultMachine.AddAnyTransition(s, event, handler, to...)
+}
@@ -0,0 +1,74 @@
+package fsmv1_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+func TestHierarchy(t *testing.T) {
+	var m = fsmv1.NewMachine()
+	var calls []string
+
+	var record = func(name string) fsmv1.StateHook {
+		return func(state fsmv1.State, v ...interface{}) error {
+			calls = append(calls, fmt.Sprintf("%s:%s", name, state))
+			return nil
+		}
+	}
+
+	var err = m.Define("order").
+		Initial("pending").
+		Final("cancelled", "expired", "done").
+		Parent("pending", "active").
+		Parent("paid", "active").
+		On("pending", "pay", to("paid"), "paid").
+		On("paid", "finish", to("done"), "done").
+		On("active", "cancel", to("cancelled"), "cancelled").
+		OnAny("timeout", to("expired"), "expired").
+		OnExit("pending", record("exit")).
+		OnExit("active", record("exit")).
+		OnEnter("cancelled", record("enter")).
+		OnEnter("paid", record("enter")).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 继承父状态的事件, 离开时由内向外调用 exit
+	if s, err := m.Do("order", "pending", "cancel"); err != nil || s != "cancelled" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+	var expect = []string{"exit:pending", "exit:active", "enter:cancelled"}
+	if !reflect.DeepEqual(calls, expect) {
+		t.Fatalf("got %v, expect %v", calls, expect)
+	}
+
+	// 同一父状态内转换不离开父状态
+	calls = nil
+	if s, err := m.Do("order", "pending", "pay"); err != nil || s != "paid" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+	expect = []string{"exit:pending", "enter:paid"}
+	if !reflect.DeepEqual(calls, expect) {
+		t.Fatalf("got %v, expect %v", calls, expect)
+	}
+
+	// 任意状态
+	if s, err := m.Do("order", "done", "timeout"); err != nil || s != "expired" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+	if _, err := m.Do("order", "done", "cancel"); err == nil || err.Error() != "event(cancel) has no handlers" {
+		t.Fatalf("expect no handlers error, got %v", err)
+	}
+
+	if err := m.Validate("order"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetParent("order", "active", "pending"); err == nil {
+		t.Fatal("expect cycle error")
+	}
+}
@@ -0,0 +1,263 @@
+package fsmv1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record 实例的一次状态转换记录
+type Record[S comparable, E comparable, P any] struct {
+	ID      string    `json:"id"`
+	From    S         `json:"from"`
+	To      S         `json:"to"` // 失败时为零值
+	Event   E         `json:"event"`
+	Time    time.Time `json:"time"`
+	Payload P         `json:"payload"`
+	Err     string    `json:"error,omitempty"` // 失败原因, 成功时为空
+}
+
+// Recorder 状态转换记录器
+type Recorder[S comparable, E comparable, P any] interface {
+	// Record 追加一条记录
+	Record(ctx context.Context, r Record[S, E, P]) error
+
+	// Records 按追加顺序返回实体的全部记录
+	Records(ctx context.Context, id string) ([]Record[S, E, P], error)
+}
+
+// WithRecorder 设置记录器, 需在使用实例前调用
+// 设置后 Fire 的每次状态转换(含失败)都会被记录
+func (i *Instance[S, E, P]) WithRecorder(r Recorder[S, E, P]) *Instance[S, E, P] {
+	i.recorder = r
+	return i
+}
+
+// record 记录一次状态转换, 未设置记录器时忽略
+func (i *Instance[S, E, P]) record(ctx context.Context, from S, event E, to S, payload P, err error) error {
+	if i.recorder == nil {
+		return nil
+	}
+
+	var r = Record[S, E, P]{
+		ID:      i.id,
+		From:    from,
+		To:      to,
+		Event:   event,
+		Time:    i.now(),
+		Payload: payload,
+	}
+	if err != nil {
+		r.Err = err.Error()
+	}
+
+	if rErr := i.recorder.Record(ctx, r); rErr != nil {
+		return fmt.Errorf("instance(%s) record history error: %w", i.id, rErr)
+	}
+	return nil
+}
+
+// now 当前时间, 设置了调度器时使用调度器的时钟
+func (i *Instance[S, E, P]) now() time.Time {
+	if i.scheduler != nil {
+		return i.scheduler.clock.Now()
+	}
+	return time.Now()
+}
+
+// Rebuild 从记录器回放实体的全部记录, 重建当前状态并写入存储
+func (i *Instance[S, E, P]) Rebuild(ctx context.Context) (S, error) {
+	var zero S
+
+	if i.recorder == nil {
+		return zero, fmt.Errorf("instance(%s) has no recorder", i.id)
+	}
+
+	var initial, ok = i.m.Initial()
+	if !ok {
+		return zero, fmt.Errorf("scheme (%s) has no initial state", i.m.Name())
+	}
+
+	var records, err = i.recorder.Records(ctx, i.id)
+	if err != nil {
+		return zero, err
+	}
+
+	state, err := Replay(i.m, initial, records)
+	if err != nil {
+		return zero, err
+	}
+
+	var _, version, lErr = i.store.Load(ctx, i.id)
+	if lErr != nil && !errors.Is(lErr, ErrNotFound) {
+		return zero, lErr
+	}
+
+	if _, err := i.store.CompareAndSwap(ctx, i.id, version, state); err != nil {
+		return zero, err
+	}
+	return state, nil
+}
+
+// Replay 从 initial 开始按顺序回放成功的记录, 返回最终状态
+// 回放不调用 handler/guard/hook, 只校验每条记录与状态机定义一致:
+// 记录的 From 等于当前状态, 该状态下定义了该事件, 且 To 为允许的目标状态
+func Replay[S comparable, E comparable, P any](m *TypedMachine[S, E, P], initial S, records []Record[S, E, P]) (S, error) {
+	var state = initial
+
+	for idx, r := range records {
+		if r.Err != "" {
+			continue
+		}
+
+		if r.From != state {
+			return state, fmt.Errorf("replay record %d: from state(%v) mismatch current state(%v)", idx, r.From, state)
+		}
+
+		var tr, err = m.transition(state, r.Event)
+		if err != nil {
+			return state, fmt.Errorf("replay record %d: %w", idx, err)
+		}
+
+		if !tr.allow(r.To) {
+			return state, fmt.Errorf("replay record %d: %w", idx, &TypedTransitionError[S, E]{
+				Scheme:  m.name,
+				From:    state,
+				Event:   r.Event,
+				To:      r.To,
+				Allowed: append([]S(nil), tr.to...),
+			})
+		}
+
+		state = r.To
+	}
+
+	return state, nil
+}
+
+// MemoryRecorder 内存记录器, 并发安全
+type MemoryRecorder[S comparable, E comparable, P any] struct {
+	mux sync.RWMutex
+
+	records map[string][]Record[S, E, P]
+}
+
+// NewMemoryRecorder 初始化
+func NewMemoryRecorder[S comparable, E comparable, P any]() *MemoryRecorder[S, E, P] {
+	return &MemoryRecorder[S, E, P]{
+		records: make(map[string][]Record[S, E, P]),
+	}
+}
+
+// Record 追加一条记录
+func (r *MemoryRecorder[S, E, P]) Record(ctx context.Context, rec Record[S, E, P]) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.records[rec.ID] = append(r.records[rec.ID], rec)
+	return nil
+}
+
+// Records 返回实体的全部记录
+func (r *MemoryRecorder[S, E, P]) Records(ctx context.Context, id string) ([]Record[S, E, P], error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return append([]Record[S, E, P](nil), r.records[id]...), nil
+}
+
+// FileRecorder 文件记录器, 每条记录以一行 JSON 追加到文件末尾, 并发安全
+// S, E, P 需能被 encoding/json 编解码
+type FileRecorder[S comparable, E comparable, P any] struct {
+	mux sync.Mutex
+
+	path string
+	file *os.File
+}
+
+// OpenFileRecorder 以追加方式打开(或创建)记录文件
+func OpenFileRecorder[S comparable, E comparable, P any](path string) (*FileRecorder[S, E, P], error) {
+	var f, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileRecorder[S, E, P]{
+		path: path,
+		file: f,
+	}, nil
+}
+
+// Record 追加一条记录
+func (r *FileRecorder[S, E, P]) Record(ctx context.Context, rec Record[S, E, P]) error {
+	var bs, err = json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	bs = append(bs, '\n')
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if _, err := r.file.Write(bs); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Records 读取文件, 返回实体的全部记录
+func (r *FileRecorder[S, E, P]) Records(ctx context.Context, id string) ([]Record[S, E, P], error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	var f, err = os.Open(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { var _ = f.Close() }()
+
+	var list []Record[S, E, P]
+	var scanner = bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec Record[S, E, P]
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d decode record error: %w", r.path, line, err)
+		}
+
+		if rec.ID == id {
+			list = append(list, rec)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Sync 将已写入的记录刷到磁盘
+func (r *FileRecorder[S, E, P]) Sync() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.file.Sync()
+}
+
+// Close 关闭文件
+func (r *FileRecorder[S, E, P]) Close() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	return r.file.Close()
+}
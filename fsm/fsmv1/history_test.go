@@ -0,0 +1,103 @@
+package fsmv1_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+type ticketPayload struct {
+	Operator string `json:"operator"`
+}
+
+func historyMachine(t *testing.T) *fsmv1.TypedMachine[string, string, ticketPayload] {
+	var m = fsmv1.NewTypedMachine[string, string, ticketPayload]("ticket")
+	m.SetInitial("open")
+
+	var next = func(s string) fsmv1.TypedHandler[string, ticketPayload] {
+		return func(context.Context, ticketPayload) (string, error) {
+			return s, nil
+		}
+	}
+
+	if err := m.AddTransition("open", "assign", next("assigned"), "assigned"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddTransition("assigned", "close", next("closed"), "closed"); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func testRecorder(t *testing.T, rec fsmv1.Recorder[string, string, ticketPayload]) {
+	var m = historyMachine(t)
+	var ctx = context.Background()
+	var store = fsmv1.NewMemoryStore[string]()
+
+	var inst = fsmv1.NewInstance[string, string, ticketPayload](m, store, "t-1").WithRecorder(rec)
+
+	if _, err := inst.Fire(ctx, "assign", ticketPayload{Operator: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.Fire(ctx, "assign", ticketPayload{Operator: "bob"}); err == nil {
+		t.Fatal("expect error for undefined event")
+	}
+	if _, err := inst.Fire(ctx, "close", ticketPayload{Operator: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var records, err = rec.Records(ctx, "t-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expect 3 records, got %d", len(records))
+	}
+	if r := records[0]; r.From != "open" || r.To != "assigned" || r.Event != "assign" || r.Payload.Operator != "alice" || r.Time.IsZero() {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	if r := records[1]; r.Err == "" || r.To != "" {
+		t.Fatalf("expect failed record, got %+v", r)
+	}
+
+	state, err := fsmv1.Replay(m, "open", records)
+	if err != nil || state != "closed" {
+		t.Fatalf("replay got %s, %v", state, err)
+	}
+
+	// 丢失存储后从历史重建
+	var rebuilt = fsmv1.NewInstance[string, string, ticketPayload](m, fsmv1.NewMemoryStore[string](), "t-1").WithRecorder(rec)
+	if s, err := rebuilt.Rebuild(ctx); err != nil || s != "closed" {
+		t.Fatalf("rebuild got %s, %v", s, err)
+	}
+	if s, err := rebuilt.State(ctx); err != nil || s != "closed" {
+		t.Fatalf("state got %s, %v", s, err)
+	}
+}
+
+func TestMemoryRecorder(t *testing.T) {
+	testRecorder(t, fsmv1.NewMemoryRecorder[string, string, ticketPayload]())
+}
+
+func TestFileRecorder(t *testing.T) {
+	var rec, err = fsmv1.OpenFileRecorder[string, string, ticketPayload](filepath.Join(t.TempDir(), "history.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { var _ = rec.Close() }()
+
+	testRecorder(t, rec)
+}
+
+func TestReplayMismatch(t *testing.T) {
+	var m = historyMachine(t)
+
+	var records = []fsmv1.Record[string, string, ticketPayload]{
+		{ID: "t-2", From: "open", To: "closed", Event: "assign"},
+	}
+	if _, err := fsmv1.Replay(m, "open", records); err == nil {
+		t.Fatal("expect illegal transition error")
+	}
+}
@@ -0,0 +1,71 @@
+package fsmv1
+
+import "context"
+
+// Guard 守卫方法, 在处理方法之前调用, 返回错误时拒绝该事件
+type Guard func(v ...interface{}) error
+
+// StateHook 进入/离开状态时的回调, 返回错误时终止状态转换
+type StateHook func(state State, v ...interface{}) error
+
+// AfterHook 状态转换成功后的回调, 可用于审计日志
+type AfterHook func(scheme Scheme, from State, event Event, to State, v ...interface{})
+
+func wrapGuard(g Guard) TypedGuard[[]interface{}] {
+	if g == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, v []interface{}) error {
+		return g(v...)
+	}
+}
+
+func wrapStateHook(h StateHook) TypedStateHook[State, []interface{}] {
+	if h == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, state State, v []interface{}) error {
+		return h(state, v...)
+	}
+}
+
+func wrapAfterHook(s Scheme, h AfterHook) TypedAfterHook[State, Event, []interface{}] {
+	if h == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, from State, event Event, to State, v []interface{}) {
+		h(s, from, event, to, v...)
+	}
+}
+
+// AddGuard 为 state 下的 event 添加守卫方法, 需先定义处理方法
+// 多个守卫按添加顺序调用, 任一返回错误则 Do 返回 *GuardError, 处理方法不会被调用
+func (m *Machine) AddGuard(s Scheme, state State, event Event, name string, g Guard) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.AddGuard(state, event, name, wrapGuard(g))
+	})
+}
+
+// OnEnter 添加进入 state 时的回调
+func (m *Machine) OnEnter(s Scheme, state State, hook StateHook) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.OnEnter(state, wrapStateHook(hook))
+	})
+}
+
+// OnExit 添加离开 state 时的回调
+func (m *Machine) OnExit(s Scheme, state State, hook StateHook) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.OnExit(state, wrapStateHook(hook))
+	})
+}
+
+// AfterTransition 添加状态转换成功后的回调
+func (m *Machine) AfterTransition(s Scheme, hook AfterHook) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.AfterTransition(wrapAfterHook(s, hook))
+	})
+}
@@ -0,0 +1,105 @@
+package fsmv1_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+func TestHooks(t *testing.T) {
+	var m = fsmv1.NewMachine()
+	var calls []string
+	var deny error
+
+	var record = func(name string) fsmv1.StateHook {
+		return func(state fsmv1.State, v ...interface{}) error {
+			calls = append(calls, fmt.Sprintf("%s:%s", name, state))
+			return nil
+		}
+	}
+
+	var err = m.Define("order").
+		On("created", "pay", func(v ...interface{}) (fsmv1.State, error) {
+			calls = append(calls, "handler")
+			return "paid", nil
+		}, "paid").
+		Guard("created", "pay", "balance", func(v ...interface{}) error {
+			calls = append(calls, "guard")
+			return deny
+		}).
+		On("paid", "noop", to("paid")).
+		OnExit("created", record("exit")).
+		OnEnter("paid", record("enter")).
+		After(func(s fsmv1.Scheme, from fsmv1.State, e fsmv1.Event, to fsmv1.State, v ...interface{}) {
+			calls = append(calls, fmt.Sprintf("after:%s-%s->%s", from, e, to))
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Do("order", "created", "pay"); err != nil {
+		t.Fatal(err)
+	}
+	var expect = []string{"guard", "handler", "exit:created", "enter:paid", "after:created-pay->paid"}
+	if !reflect.DeepEqual(calls, expect) {
+		t.Fatalf("got %v, expect %v", calls, expect)
+	}
+
+	// 自身转换不触发 enter/exit
+	calls = nil
+	if _, err := m.Do("order", "paid", "noop"); err != nil {
+		t.Fatal(err)
+	}
+	expect = []string{"after:paid-noop->paid"}
+	if !reflect.DeepEqual(calls, expect) {
+		t.Fatalf("got %v, expect %v", calls, expect)
+	}
+
+	// 守卫拒绝, 处理方法不被调用
+	calls = nil
+	deny = errors.New("insufficient balance")
+	var s, gErr = m.Do("order", "created", "pay")
+	var ge *fsmv1.GuardError
+	if !errors.As(gErr, &ge) || ge.Guard != "balance" || !errors.Is(gErr, deny) || s != "" {
+		t.Fatalf("expect GuardError, got %s, %v", s, gErr)
+	}
+	if !reflect.DeepEqual(calls, []string{"guard"}) {
+		t.Fatalf("unexpected calls %v", calls)
+	}
+}
+
+func TestHookError(t *testing.T) {
+	var m = fsmv1.NewMachine()
+	var fail = errors.New("enter failed")
+	var after bool
+
+	if err := m.Register("ticket"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddHandler("ticket", "open", "close", to("closed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.OnEnter("ticket", "closed", func(fsmv1.State, ...interface{}) error { return fail }); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AfterTransition("ticket", func(fsmv1.Scheme, fsmv1.State, fsmv1.Event, fsmv1.State, ...interface{}) {
+		after = true
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Do("ticket", "open", "close"); !errors.Is(err, fail) {
+		t.Fatalf("expect enter error, got %v", err)
+	}
+	if after {
+		t.Fatal("after hook must not run when transition failed")
+	}
+
+	if err := m.AddGuard("ticket", "open", "reopen", "g", func(...interface{}) error { return nil }); err == nil {
+		t.Fatal("expect error adding guard on undefined event")
+	}
+}
@@ -0,0 +1,199 @@
+package fsmv1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNotFound 存储中不存在该实体
+var ErrNotFound = errors.New("fsm: instance not found")
+
+// ErrConflict 乐观锁冲突, 可通过 errors.Is(err, ErrConflict) 判断
+var ErrConflict = errors.New("fsm: version conflict")
+
+// ConflictError 保存时版本号不匹配, 说明实体已被并发修改
+type ConflictError struct {
+	ID       string
+	Expected uint64 // 读取时的版本
+	Actual   uint64 // 存储中的当前版本
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("instance(%s) version conflict, expected %d, actual %d", e.ID, e.Expected, e.Actual)
+}
+
+// Is 支持 errors.Is(err, ErrConflict)
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Store 实体状态存储
+// 版本号从 1 开始, 每次保存加一, 0 表示实体尚不存在
+type Store[S comparable] interface {
+	// Load 读取实体当前状态及版本, 不存在时返回 ErrNotFound
+	Load(ctx context.Context, id string) (S, uint64, error)
+
+	// CompareAndSwap 当存储中的版本等于 version 时保存 state, 返回新版本
+	// version 为 0 表示新建; 版本不匹配时返回 *ConflictError
+	CompareAndSwap(ctx context.Context, id string, version uint64, state S) (uint64, error)
+}
+
+// Instance 以实体ID标识的状态机实例, 状态保存在 Store 中
+type Instance[S comparable, E comparable, P any] struct {
+	m     *TypedMachine[S, E, P]
+	store Store[S]
+	id    string
+
+	recorder  Recorder[S, E, P]   // 可选, 记录状态转换历史
+	scheduler *Scheduler[S, E, P] // 可选, 状态超时调度
+}
+
+// NewInstance 初始化, 实体不存在时以状态机的初始状态开始
+func NewInstance[S comparable, E comparable, P any](m *TypedMachine[S, E, P], store Store[S], id string) *Instance[S, E, P] {
+	return &Instance[S, E, P]{
+		m:     m,
+		store: store,
+		id:    id,
+	}
+}
+
+// ID 实体ID
+func (i *Instance[S, E, P]) ID() string {
+	return i.id
+}
+
+// load 读取当前状态及版本, 实体不存在时返回初始状态及版本 0
+func (i *Instance[S, E, P]) load(ctx context.Context) (S, uint64, error) {
+	var state, version, err = i.store.Load(ctx, i.id)
+	if err == nil {
+		return state, version, nil
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		return state, 0, err
+	}
+
+	var initial, ok = i.m.Initial()
+	if !ok {
+		return initial, 0, fmt.Errorf("instance(%s) not found and scheme (%s) has no initial state", i.id, i.m.Name())
+	}
+	return initial, 0, nil
+}
+
+// State 返回实体当前状态
+func (i *Instance[S, E, P]) State(ctx context.Context) (S, error) {
+	var state, _, err = i.load(ctx)
+	return state, err
+}
+
+// Fire 对实体触发事件: 读取状态 -> guard/handler 计算新状态 -> 按读取时的版本保存新状态
+// -> OnExit/OnEnter/after -> 重置超时定时器 -> 记录历史
+// 实体被并发修改时返回 *ConflictError, 此时只执行了 guard/handler, 不调用 OnExit/OnEnter/after, 调用方可重新读取后重试;
+// 新状态已保存后 OnExit/OnEnter 或记录历史失败时, 返回新状态及该错误
+func (i *Instance[S, E, P]) Fire(ctx context.Context, event E, payload P) (S, error) {
+	var state, version, err = i.load(ctx)
+	if err != nil {
+		var zero S
+		return zero, err
+	}
+
+	return i.fireAt(ctx, state, version, event, payload)
+}
+
+// fireAt 以已读取的状态及版本触发事件, 保存时版本不匹配返回 *ConflictError
+func (i *Instance[S, E, P]) fireAt(ctx context.Context, state S, version uint64, event E, payload P) (S, error) {
+	var zero S
+
+	var next, dErr = i.m.prepare(ctx, state, event, payload)
+	if dErr == nil {
+		_, dErr = i.store.CompareAndSwap(ctx, i.id, version, next)
+	}
+
+	if dErr != nil {
+		if rErr := i.record(ctx, state, event, zero, payload, dErr); rErr != nil {
+			return zero, fmt.Errorf("%w (%v)", dErr, rErr)
+		}
+		return zero, dErr
+	}
+
+	// 新状态已保存, hook 失败不影响调度及记录
+	var hErr = i.m.commit(ctx, state, event, next, payload)
+
+	if i.scheduler != nil && next != state {
+		i.scheduler.schedule(i, next)
+	}
+
+	var rErr = i.record(ctx, state, event, next, payload, nil)
+	if hErr != nil {
+		if rErr != nil {
+			return next, fmt.Errorf("%w (%v)", hErr, rErr)
+		}
+		return next, hErr
+	}
+	return next, rErr
+}
+
+// Instance 返回 scheme 下以 id 标识的实例
+func (m *Machine) Instance(s Scheme, store Store[State], id string) (*Instance[State, Event, []interface{}], error) {
+	var sc, err = m.lookup(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInstance(sc, store, id), nil
+}
+
+// MemoryStore 内存存储, 并发安全
+type MemoryStore[S comparable] struct {
+	mux sync.Mutex
+
+	entries map[string]memoryEntry[S]
+}
+
+type memoryEntry[S comparable] struct {
+	state   S
+	version uint64
+}
+
+// NewMemoryStore 初始化
+func NewMemoryStore[S comparable]() *MemoryStore[S] {
+	return &MemoryStore[S]{
+		entries: make(map[string]memoryEntry[S]),
+	}
+}
+
+// Load 读取实体当前状态及版本
+func (s *MemoryStore[S]) Load(ctx context.Context, id string) (S, uint64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var e, ok = s.entries[id]
+	if !ok {
+		var zero S
+		return zero, 0, ErrNotFound
+	}
+	return e.state, e.version, nil
+}
+
+// CompareAndSwap 版本匹配时保存状态
+func (s *MemoryStore[S]) CompareAndSwap(ctx context.Context, id string, version uint64, state S) (uint64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var e = s.entries[id]
+	if e.version != version {
+		return 0, &ConflictError{
+			ID:       id,
+			Expected: version,
+			Actual:   e.version,
+		}
+	}
+
+	s.entries[id] = memoryEntry[S]{
+		state:   state,
+		version: version + 1,
+	}
+	return version + 1, nil
+}
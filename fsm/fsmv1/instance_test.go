@@ -0,0 +1,114 @@
+package fsmv1_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+func TestInstance(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	var err = m.Define("order").
+		Initial("created").
+		On("created", "pay", to("paid"), "paid").
+		On("paid", "ship", to("shipped"), "shipped").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var store = fsmv1.NewMemoryStore[fsmv1.State]()
+	var ctx = context.Background()
+
+	inst, err := m.Instance("order", store, "o-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err := inst.State(ctx); err != nil || s != "created" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+	if s, err := inst.Fire(ctx, "pay", nil); err != nil || s != "paid" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+	if _, err := inst.Fire(ctx, "pay", nil); err == nil {
+		t.Fatal("expect no handler error for paid state")
+	}
+	if s, err := inst.Fire(ctx, "ship", nil); err != nil || s != "shipped" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+
+	var s, v, _ = store.Load(ctx, "o-1")
+	if s != "shipped" || v != 2 {
+		t.Fatalf("got %s, version %d", s, v)
+	}
+}
+
+func TestInstanceConflict(t *testing.T) {
+	var m = fsmv1.NewTypedMachine[string, string, struct{}]("counter")
+	m.SetInitial("a")
+
+	var start = make(chan struct{})
+	var ready sync.WaitGroup
+	ready.Add(2)
+
+	if err := m.AddTransition("a", "go", func(ctx context.Context, _ struct{}) (string, error) {
+		// 两个并发请求都读取到状态 a 后再继续
+		ready.Done()
+		<-start
+		return "b", nil
+	}, "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 冲突的一方未保存, 不调用 OnExit/OnEnter/after
+	var hooks int32
+	var countHook = func(ctx context.Context, state string, _ struct{}) error {
+		atomic.AddInt32(&hooks, 1)
+		return nil
+	}
+	m.OnExit("a", countHook)
+	m.OnEnter("b", countHook)
+	m.AfterTransition(func(ctx context.Context, from string, event string, to string, _ struct{}) {
+		atomic.AddInt32(&hooks, 1)
+	})
+
+	var store = fsmv1.NewMemoryStore[string]()
+	var errs = make(chan error, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			var _, err = fsmv1.NewInstance[string, string, struct{}](m, store, "c-1").Fire(context.Background(), "go", struct{}{})
+			errs <- err
+		}()
+	}
+
+	ready.Wait()
+	close(start)
+
+	var ok, conflict int
+	for i := 0; i < 2; i++ {
+		var err = <-errs
+		var ce *fsmv1.ConflictError
+		switch {
+		case err == nil:
+			ok++
+		case errors.Is(err, fsmv1.ErrConflict) && errors.As(err, &ce):
+			conflict++
+		default:
+			t.Fatal(err)
+		}
+	}
+
+	if ok != 1 || conflict != 1 {
+		t.Fatalf("expect exactly one success, got ok %d, conflict %d", ok, conflict)
+	}
+	if hooks != 3 {
+		t.Fatalf("expect hooks of one transition, got %d calls", hooks)
+	}
+}
@@ -0,0 +1,196 @@
+package fsmv1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// stateTimeout 状态超时定义
+type stateTimeout[E comparable] struct {
+	d     time.Duration
+	event E
+}
+
+// SetTimeout 实例在 state 停留超过 d 后自动触发 event, 需配合 Scheduler 使用
+func (m *TypedMachine[S, E, P]) SetTimeout(state S, d time.Duration, event E) error {
+	if d <= 0 {
+		return fmt.Errorf("state(%v) timeout(%s) must be positive", state, d)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.timeouts[state] = stateTimeout[E]{d: d, event: event}
+	return nil
+}
+
+// Timeout 返回 state 的超时定义
+func (m *TypedMachine[S, E, P]) Timeout(state S) (time.Duration, E, bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var t, ok = m.timeouts[state]
+	return t.d, t.event, ok
+}
+
+// Scheduler 状态超时调度器, 同一个 Scheduler 只用于同一状态机的实例, 以实体ID区分
+type Scheduler[S comparable, E comparable, P any] struct {
+	mux sync.Mutex
+
+	clock  Clock
+	timers map[string]*scheduled[S]
+	seq    uint64
+
+	onError func(id string, err error)
+}
+
+// scheduled 实例当前的超时定时器
+type scheduled[S comparable] struct {
+	seq   uint64
+	state S
+	timer Timer
+}
+
+// NewScheduler 初始化, clock 为 nil 时使用 SystemClock
+// onError 可选, 超时事件触发失败时调用
+func NewScheduler[S comparable, E comparable, P any](clock Clock, onError func(id string, err error)) *Scheduler[S, E, P] {
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	return &Scheduler[S, E, P]{
+		clock:   clock,
+		timers:  make(map[string]*scheduled[S]),
+		onError: onError,
+	}
+}
+
+// WithScheduler 设置调度器, 需在使用实例前调用
+// 设置后 Fire 进入新状态时取消旧状态的定时器, 并按新状态的超时定义启动定时器
+func (i *Instance[S, E, P]) WithScheduler(s *Scheduler[S, E, P]) *Instance[S, E, P] {
+	i.scheduler = s
+	return i
+}
+
+// Schedule 按实例当前状态启动超时定时器, 用于新建实例或进程重启后恢复
+// 定时器从调用时开始计时
+func (i *Instance[S, E, P]) Schedule(ctx context.Context) error {
+	if i.scheduler == nil {
+		return fmt.Errorf("instance(%s) has no scheduler", i.id)
+	}
+
+	var state, err = i.State(ctx)
+	if err != nil {
+		return err
+	}
+
+	i.scheduler.schedule(i, state)
+	return nil
+}
+
+// schedule 取消实例已有的定时器, 若 state 定义了超时则启动新的定时器
+func (s *Scheduler[S, E, P]) schedule(i *Instance[S, E, P], state S) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if old, ok := s.timers[i.id]; ok {
+		old.timer.Stop()
+		delete(s.timers, i.id)
+	}
+
+	var d, event, ok = i.m.Timeout(state)
+	if !ok {
+		return
+	}
+
+	s.seq++
+	var seq = s.seq
+
+	s.timers[i.id] = &scheduled[S]{
+		seq:   seq,
+		state: state,
+		timer: s.clock.AfterFunc(d, func() {
+			s.expire(i, seq, state, event)
+		}),
+	}
+}
+
+// expire 定时器到期, 实例仍处于 state 时触发 event, 实例已离开该状态 (含并发修改) 时忽略
+func (s *Scheduler[S, E, P]) expire(i *Instance[S, E, P], seq uint64, state S, event E) {
+	s.mux.Lock()
+	var curr, ok = s.timers[i.id]
+	if !ok || curr.seq != seq {
+		// 已被取消或替换
+		s.mux.Unlock()
+		return
+	}
+	delete(s.timers, i.id)
+	s.mux.Unlock()
+
+	var ctx = context.Background()
+
+	// 按读取到的版本触发, 读取之后实例被并发修改时保存失败, 不会对新状态触发超时事件
+	var now, version, err = i.load(ctx)
+	if err == nil && now != state {
+		// 实例已通过其他途径离开该状态
+		return
+	}
+
+	if err == nil {
+		var payload P
+		_, err = i.fireAt(ctx, now, version, event, payload)
+		if errors.Is(err, ErrConflict) {
+			// 读取之后实例已被修改, 视为已离开该状态
+			return
+		}
+	}
+
+	if err != nil && s.onError != nil {
+		s.onError(i.id, fmt.Errorf("instance(%s) state(%v) timeout event(%v) error: %w", i.id, state, event, err))
+	}
+}
+
+// Cancel 取消实例的超时定时器
+func (s *Scheduler[S, E, P]) Cancel(id string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if old, ok := s.timers[id]; ok {
+		old.timer.Stop()
+		delete(s.timers, id)
+	}
+}
+
+// Pending 返回实例等待超时的状态
+func (s *Scheduler[S, E, P]) Pending(id string) (S, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	var t, ok = s.timers[id]
+	if !ok {
+		var zero S
+		return zero, false
+	}
+	return t.state, true
+}
+
+// Stop 取消全部定时器
+func (s *Scheduler[S, E, P]) Stop() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for id, t := range s.timers {
+		t.timer.Stop()
+		delete(s.timers, id)
+	}
+}
+
+// SetTimeout 实例在 state 停留超过 d 后自动触发 event
+func (m *Machine) SetTimeout(s Scheme, state State, d time.Duration, event Event) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.SetTimeout(state, d, event)
+	})
+}
@@ -0,0 +1,170 @@
+package fsmv1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+func TestTimeout(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	var err = m.Define("order").
+		Initial("created").
+		On("created", "submit", to("pending_payment"), "pending_payment").
+		On("pending_payment", "pay", to("paid"), "paid").
+		On("pending_payment", "expire", to("expired"), "expired").
+		Timeout("pending_payment", 30*time.Minute, "expire").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ctx = context.Background()
+	var clock = fsmv1.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var errs []error
+	var sched = fsmv1.NewScheduler[fsmv1.State, fsmv1.Event, []interface{}](clock, func(id string, err error) {
+		errs = append(errs, err)
+	})
+	var store = fsmv1.NewMemoryStore[fsmv1.State]()
+
+	var newInstance = func(id string) *fsmv1.Instance[fsmv1.State, fsmv1.Event, []interface{}] {
+		var inst, err = m.Instance("order", store, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return inst.WithScheduler(sched)
+	}
+
+	// 超时自动触发 expire
+	var o1 = newInstance("o-1")
+	if _, err := o1.Fire(ctx, "submit", nil); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := sched.Pending("o-1"); !ok || s != "pending_payment" {
+		t.Fatalf("expect pending timer, got %s, %v", s, ok)
+	}
+
+	clock.Advance(29 * time.Minute)
+	if s, _ := o1.State(ctx); s != "pending_payment" {
+		t.Fatalf("expired too early: %s", s)
+	}
+	clock.Advance(time.Minute)
+	if s, _ := o1.State(ctx); s != "expired" {
+		t.Fatalf("expect expired, got %s", s)
+	}
+
+	// 离开状态后取消定时器
+	var o2 = newInstance("o-2")
+	if _, err := o2.Fire(ctx, "submit", nil); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(10 * time.Minute)
+	if _, err := o2.Fire(ctx, "pay", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sched.Pending("o-2"); ok {
+		t.Fatal("timer must be cancelled after leaving state")
+	}
+	clock.Advance(time.Hour)
+	if s, _ := o2.State(ctx); s != "paid" {
+		t.Fatalf("expect paid, got %s", s)
+	}
+
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	var clock = fsmv1.NewFakeClock(time.Unix(0, 0))
+	var fired []int
+
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	clock.AfterFunc(time.Second, func() {
+		fired = append(fired, 1)
+		clock.AfterFunc(time.Second/2, func() { fired = append(fired, 15) })
+	})
+	var stopped = clock.AfterFunc(time.Second, func() { fired = append(fired, -1) })
+
+	if !stopped.Stop() || stopped.Stop() {
+		t.Fatal("unexpected Stop result")
+	}
+
+	clock.Advance(3 * time.Second)
+	if len(fired) != 3 || fired[0] != 1 || fired[1] != 15 || fired[2] != 2 {
+		t.Fatalf("unexpected fire order %v", fired)
+	}
+	if !clock.Now().Equal(time.Unix(3, 0)) {
+		t.Fatalf("unexpected now %v", clock.Now())
+	}
+}
+
+// racyStore 读取后调用一次 afterLoad, 模拟其他进程在读取与保存之间修改实例
+type racyStore struct {
+	*fsmv1.MemoryStore[fsmv1.State]
+	afterLoad func()
+}
+
+func (s *racyStore) Load(ctx context.Context, id string) (fsmv1.State, uint64, error) {
+	var state, version, err = s.MemoryStore.Load(ctx, id)
+	if f := s.afterLoad; f != nil {
+		s.afterLoad = nil
+		f()
+	}
+	return state, version, err
+}
+
+func TestTimeoutConcurrentFire(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	// expire 在任意状态下都可触发, 超时事件不能作用于并发修改后的新状态
+	var err = m.Define("order").
+		Initial("created").
+		On("created", "submit", to("pending_payment"), "pending_payment").
+		On("pending_payment", "pay", to("paid"), "paid").
+		OnAny("expire", to("expired"), "expired").
+		Timeout("pending_payment", 30*time.Minute, "expire").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ctx = context.Background()
+	var clock = fsmv1.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var errs []error
+	var sched = fsmv1.NewScheduler[fsmv1.State, fsmv1.Event, []interface{}](clock, func(id string, err error) {
+		errs = append(errs, err)
+	})
+	var store = &racyStore{MemoryStore: fsmv1.NewMemoryStore[fsmv1.State]()}
+
+	inst, err := m.Instance("order", store, "o-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst.WithScheduler(sched)
+	if _, err := inst.Fire(ctx, "submit", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他进程 (未共享调度器) 在超时读取状态之后完成支付
+	other, err := m.Instance("order", store.MemoryStore, "o-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.afterLoad = func() {
+		if _, err := other.Fire(ctx, "pay", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock.Advance(30 * time.Minute)
+	if s, _ := inst.State(ctx); s != "paid" {
+		t.Fatalf("expect paid, got %s", s)
+	}
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+}
@@ -0,0 +1,356 @@
+package fsmv1
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// TypedHandler 带类型的处理方法, 返回新的状态
+type TypedHandler[S comparable, P any] func(ctx context.Context, payload P) (S, error)
+
+// TypedGuard 带类型的守卫方法, 在处理方法之前调用, 返回错误时拒绝该事件
+type TypedGuard[P any] func(ctx context.Context, payload P) error
+
+// TypedStateHook 带类型的进入/离开状态回调, 返回错误时终止状态转换
+type TypedStateHook[S comparable, P any] func(ctx context.Context, state S, payload P) error
+
+// TypedAfterHook 带类型的状态转换成功后回调
+type TypedAfterHook[S comparable, E comparable, P any] func(ctx context.Context, from S, event E, to S, payload P)
+
+// TypedMachine 泛型状态机, S: 状态类型, E: 事件类型, P: 处理方法参数类型, 并发安全
+// 字符串版本的 Machine 中每个 Scheme 即一个 TypedMachine[State, Event, []interface{}]
+type TypedMachine[S comparable, E comparable, P any] struct {
+	mux sync.RWMutex
+
+	name  Scheme
+	table map[S]map[E]*typedTransition[S, P]
+
+	parent map[S]S                      // 父状态
+	any    map[E]*typedTransition[S, P] // 任意状态下的事件
+
+	enter map[S][]TypedStateHook[S, P] // 进入状态时调用
+	exit  map[S][]TypedStateHook[S, P] // 离开状态时调用
+	after []TypedAfterHook[S, E, P]    // 状态转换完成后调用
+
+	declared   map[S]struct{} // 显式声明的状态
+	initial    S              // 初始状态
+	hasInitial bool
+	final      map[S]struct{} // 终止状态
+
+	timeouts map[S]stateTimeout[E] // 状态超时
+}
+
+// typedTransition 某状态下某事件的定义
+type typedTransition[S comparable, P any] struct {
+	handler TypedHandler[S, P]
+	to      []S // 允许的目标状态, 为空时不限制
+	guards  []typedGuard[P]
+}
+
+// stateHook 绑定了所属状态的 enter/exit 回调
+type stateHook[S comparable, P any] struct {
+	state S
+	fn    TypedStateHook[S, P]
+}
+
+type typedGuard[P any] struct {
+	name string
+	fn   TypedGuard[P]
+}
+
+// allow 判断目标状态是否合法
+func (t *typedTransition[S, P]) allow(s S) bool {
+	if len(t.to) == 0 {
+		return true
+	}
+
+	for _, to := range t.to {
+		if to == s {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTypedMachine 初始化, name 用于错误信息及导出
+func NewTypedMachine[S comparable, E comparable, P any](name Scheme) *TypedMachine[S, E, P] {
+	return &TypedMachine[S, E, P]{
+		name:  name,
+		table: make(map[S]map[E]*typedTransition[S, P]),
+
+		parent: make(map[S]S),
+		any:    make(map[E]*typedTransition[S, P]),
+		enter:  make(map[S][]TypedStateHook[S, P]),
+		exit:   make(map[S][]TypedStateHook[S, P]),
+
+		declared: make(map[S]struct{}),
+		final:    make(map[S]struct{}),
+
+		timeouts: make(map[S]stateTimeout[E]),
+	}
+}
+
+// Name 状态机名称
+func (m *TypedMachine[S, E, P]) Name() Scheme {
+	return m.name
+}
+
+// AddTransition 添加处理事件, 并声明允许的目标状态, {to} 为空时不限制
+// 处理方法返回 {to} 以外的状态时, Do 返回 *TypedTransitionError
+func (m *TypedMachine[S, E, P]) AddTransition(state S, event E, handler TypedHandler[S, P], to ...S) error {
+	if handler == nil {
+		return fmt.Errorf("state(%v) event(%v) handler is nil", state, event)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if _, ok := m.table[state]; !ok {
+		m.table[state] = make(map[E]*typedTransition[S, P])
+	}
+
+	if _, ok := m.table[state][event]; ok {
+		return fmt.Errorf("state(%v) event(%v) has defined", state, event)
+	}
+
+	m.table[state][event] = &typedTransition[S, P]{
+		handler: handler,
+		to:      append([]S(nil), to...),
+	}
+	return nil
+}
+
+// AddGuard 为 state 下的 event 添加守卫方法, 需先定义处理方法
+// 多个守卫按添加顺序调用, 任一返回错误则 Do 返回 *TypedGuardError, 处理方法不会被调用
+func (m *TypedMachine[S, E, P]) AddGuard(state S, event E, name string, g TypedGuard[P]) error {
+	if g == nil {
+		return fmt.Errorf("state(%v) event(%v) guard(%s) is nil", state, event, name)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var tr, ok = m.table[state][event]
+	if !ok {
+		return fmt.Errorf("state(%v) event(%v) has no handlers", state, event)
+	}
+
+	// 写时复制, Do 中持有的旧切片不受影响
+	tr.guards = append(tr.guards[:len(tr.guards):len(tr.guards)], typedGuard[P]{name: name, fn: g})
+	return nil
+}
+
+// OnEnter 添加进入 state 时的回调
+func (m *TypedMachine[S, E, P]) OnEnter(state S, hook TypedStateHook[S, P]) error {
+	if hook == nil {
+		return fmt.Errorf("state(%v) enter hook is nil", state)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var hs = m.enter[state]
+	m.enter[state] = append(hs[:len(hs):len(hs)], hook)
+	return nil
+}
+
+// OnExit 添加离开 state 时的回调
+func (m *TypedMachine[S, E, P]) OnExit(state S, hook TypedStateHook[S, P]) error {
+	if hook == nil {
+		return fmt.Errorf("state(%v) exit hook is nil", state)
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	var hs = m.exit[state]
+	m.exit[state] = append(hs[:len(hs):len(hs)], hook)
+	return nil
+}
+
+// AfterTransition 添加状态转换成功后的回调
+func (m *TypedMachine[S, E, P]) AfterTransition(hook TypedAfterHook[S, E, P]) error {
+	if hook == nil {
+		return fmt.Errorf("after hook is nil")
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.after = append(m.after[:len(m.after):len(m.after)], hook)
+	return nil
+}
+
+// Do 状态转换
+//
+// 调用顺序: guard -> handler -> 校验目标状态 -> OnExit(state) -> OnEnter(新状态) -> after
+// 事件查找顺序: 当前状态 -> 父状态 -> ... -> 任意状态, 见 SetParent
+// guard, handler, OnExit, OnEnter 任一返回错误则终止并返回该错误, 之后的步骤不再执行;
+// 返回错误时状态均为零值;
+// 目标状态与当前状态相同时不调用 OnExit/OnEnter;
+// 每一步之前检查 ctx, ctx 结束时返回 ctx.Err()
+func (m *TypedMachine[S, E, P]) Do(ctx context.Context, state S, event E, payload P) (S, error) {
+	var next, err = m.do(ctx, state, event, payload)
+	if err != nil {
+		var zero S
+		return zero, err
+	}
+	return next, nil
+}
+
+// do 同 Do, 但 handler 返回错误时返回 handler 返回的状态, 与旧版 Machine.Do 保持一致
+func (m *TypedMachine[S, E, P]) do(ctx context.Context, state S, event E, payload P) (S, error) {
+	var next, err = m.prepare(ctx, state, event, payload)
+	if err != nil {
+		return next, err
+	}
+
+	if err := m.commit(ctx, state, event, next, payload); err != nil {
+		var zero S
+		return zero, err
+	}
+	return next, nil
+}
+
+// prepare 调用 guard 及 handler, 校验并返回目标状态, 不调用 OnExit/OnEnter/after
+// handler 返回错误时返回 handler 返回的状态
+func (m *TypedMachine[S, E, P]) prepare(ctx context.Context, state S, event E, payload P) (S, error) {
+	var zero S
+
+	var tr, err = m.transition(state, event)
+	if err != nil {
+		return zero, err
+	}
+
+	// 不持有锁调用, 允许handler/hook内部再次操作状态机
+	for _, g := range tr.guards {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+
+		if gErr := g.fn(ctx, payload); gErr != nil {
+			return zero, &TypedGuardError[S, E]{
+				Scheme: m.name,
+				State:  state,
+				Event:  event,
+				Guard:  g.name,
+				Err:    gErr,
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	var next, hErr = tr.handler(ctx, payload)
+	if hErr != nil {
+		return next, hErr
+	}
+
+	if !tr.allow(next) {
+		return zero, &TypedTransitionError[S, E]{
+			Scheme:  m.name,
+			From:    state,
+			Event:   event,
+			To:      next,
+			Allowed: append([]S(nil), tr.to...),
+		}
+	}
+
+	return next, nil
+}
+
+// commit 由 state 转换到 next: 调用 OnExit, OnEnter, 均成功后调用 after
+func (m *TypedMachine[S, E, P]) commit(ctx context.Context, state S, event E, next S, payload P) error {
+	var exit, enter, after = m.hooks(state, next)
+
+	if next != state {
+		for _, h := range exit {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := h.fn(ctx, h.state, payload); err != nil {
+				return fmt.Errorf("scheme (%s) exit state(%v) error: %w", m.name, h.state, err)
+			}
+		}
+
+		for _, h := range enter {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := h.fn(ctx, h.state, payload); err != nil {
+				return fmt.Errorf("scheme (%s) enter state(%v) error: %w", m.name, h.state, err)
+			}
+		}
+	}
+
+	for _, h := range after {
+		h(ctx, state, event, next, payload)
+	}
+
+	return nil
+}
+
+// transition 查找定义并返回副本, 副本可在锁外安全使用
+func (m *TypedMachine[S, E, P]) transition(state S, event E) (typedTransition[S, P], error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var tr, ok = m.lookup(state, event)
+	if ok {
+		return *tr, nil
+	}
+
+	if !m.hasEvents(state) {
+		return typedTransition[S, P]{}, fmt.Errorf("state(%v) has not define event and handler", state)
+	}
+	return typedTransition[S, P]{}, fmt.Errorf("event(%v) has no handlers", event)
+}
+
+// hooks 获取 from 的 exit hooks, to 的 enter hooks 以及 after hooks
+// 存在父状态时, 离开 from 及其祖先直到与 to 的最近公共祖先(不含), 由内向外调用 exit;
+// 再由外向内进入 to 的祖先直到 to, 调用 enter
+// 返回的切片均为新建或写时复制, 可在锁外安全遍历
+func (m *TypedMachine[S, E, P]) hooks(from S, to S) ([]stateHook[S, P], []stateHook[S, P], []TypedAfterHook[S, E, P]) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var fromChain, toChain = m.ancestors(from), m.ancestors(to)
+
+	var inTo = make(map[S]struct{}, len(toChain))
+	for _, s := range toChain {
+		inTo[s] = struct{}{}
+	}
+
+	var exit []stateHook[S, P]
+	var common S
+	var hasCommon bool
+	for _, s := range fromChain {
+		if _, ok := inTo[s]; ok {
+			common, hasCommon = s, true
+			break
+		}
+		for _, h := range m.exit[s] {
+			exit = append(exit, stateHook[S, P]{state: s, fn: h})
+		}
+	}
+
+	var entered []S
+	for _, s := range toChain {
+		if hasCommon && s == common {
+			break
+		}
+		entered = append(entered, s)
+	}
+
+	var enter []stateHook[S, P]
+	for i := len(entered) - 1; i >= 0; i-- {
+		for _, h := range m.enter[entered[i]] {
+			enter = append(enter, stateHook[S, P]{state: entered[i], fn: h})
+		}
+	}
+
+	return exit, enter, m.after
+}
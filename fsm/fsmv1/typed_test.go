@@ -0,0 +1,128 @@
+package fsmv1_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+type orderState int
+
+const (
+	created orderState = iota
+	paid
+	refunded
+)
+
+type orderEvent string
+
+type payment struct {
+	amount int
+}
+
+func TestTypedMachine(t *testing.T) {
+	var m = fsmv1.NewTypedMachine[orderState, orderEvent, payment]("order")
+
+	var err = m.AddTransition(created, "pay", func(ctx context.Context, p payment) (orderState, error) {
+		if p.amount <= 0 {
+			return created, errors.New("invalid amount")
+		}
+		return paid, nil
+	}, paid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.AddGuard(created, "pay", "limit", func(ctx context.Context, p payment) error {
+		if p.amount > 100 {
+			return errors.New("over limit")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var s orderState
+	if s, err = m.Do(context.Background(), created, "pay", payment{amount: 10}); err != nil || s != paid {
+		t.Fatalf("got %v, %v", s, err)
+	}
+
+	_, err = m.Do(context.Background(), created, "pay", payment{amount: 1000})
+	var ge *fsmv1.TypedGuardError[orderState, orderEvent]
+	if !errors.As(err, &ge) || ge.Guard != "limit" {
+		t.Fatalf("expect guard error, got %v", err)
+	}
+
+	// handler 返回错误时状态为零值, 忽略 handler 返回的状态
+	if err := m.AddTransition(created, "cancel", func(ctx context.Context, p payment) (orderState, error) {
+		return refunded, errors.New("cancel failed")
+	}, refunded); err != nil {
+		t.Fatal(err)
+	}
+	var zero orderState
+	if s, err = m.Do(context.Background(), created, "cancel", payment{}); err == nil || s != zero {
+		t.Fatalf("expect zero state on handler error, got %v, %v", s, err)
+	}
+
+	if err := m.AddTransition(paid, "refund", func(ctx context.Context, p payment) (orderState, error) {
+		return created, nil
+	}, refunded); err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Do(context.Background(), paid, "refund", payment{})
+	var te *fsmv1.TypedTransitionError[orderState, orderEvent]
+	if !errors.As(err, &te) || te.To != created {
+		t.Fatalf("expect transition error, got %v", err)
+	}
+}
+
+func TestTypedMachineContext(t *testing.T) {
+	var m = fsmv1.NewTypedMachine[string, string, struct{}]("slow")
+
+	var called bool
+	if err := m.AddTransition("a", "go", func(ctx context.Context, _ struct{}) (string, error) {
+		called = true
+		return "b", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddGuard("a", "go", "wait", func(ctx context.Context, _ struct{}) error {
+		<-ctx.Done()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := m.Do(ctx, "a", "go", struct{}{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+	if called {
+		t.Fatal("handler must not run after context done")
+	}
+}
+
+func TestUntypedVariadic(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	var err = m.Define("args").
+		On("a", "go", func(v ...interface{}) (fsmv1.State, error) {
+			if len(v) != 2 {
+				return "", errors.New("variadic arguments not spread")
+			}
+			return fsmv1.State(v[0].(string)), nil
+		}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err := m.Do("args", "a", "go", "b", 1); err != nil || s != "b" {
+		t.Fatalf("got %s, %v", s, err)
+	}
+}
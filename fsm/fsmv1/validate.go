@@ -0,0 +1,341 @@
+package fsmv1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProblemKind 校验问题类型
+type ProblemKind int
+
+const (
+	// MissingInitial 未声明初始状态
+	MissingInitial ProblemKind = iota
+	// UnknownState 在未知状态上定义了事件
+	UnknownState
+	// Unreachable 从初始状态不可达
+	Unreachable
+	// DeadEnd 非终止状态没有任何事件
+	DeadEnd
+)
+
+func (k ProblemKind) String() string {
+	switch k {
+	case MissingInitial:
+		return "missing initial state"
+	case UnknownState:
+		return "events defined on unknown state"
+	case Unreachable:
+		return "unreachable state"
+	case DeadEnd:
+		return "non-final state has no events"
+	}
+	return fmt.Sprintf("ProblemKind(%d)", int(k))
+}
+
+// Problem 校验发现的问题
+type Problem[S comparable] struct {
+	Kind  ProblemKind
+	State S // MissingInitial 时为零值
+}
+
+func (p Problem[S]) String() string {
+	if p.Kind == MissingInitial {
+		return p.Kind.String()
+	}
+	return fmt.Sprintf("%s: %v", p.Kind, p.State)
+}
+
+// TypedValidationError 状态机定义校验失败
+type TypedValidationError[S comparable] struct {
+	Scheme   Scheme
+	Problems []Problem[S]
+}
+
+func (e *TypedValidationError[S]) Error() string {
+	var ps = make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		ps[i] = p.String()
+	}
+	return fmt.Sprintf("scheme (%s) invalid: %s", e.Scheme, strings.Join(ps, "; "))
+}
+
+// ValidationError 字符串版本的 TypedValidationError
+type ValidationError = TypedValidationError[State]
+
+// AddStates 显式声明状态
+// 已知状态包括: 显式声明的状态, 初始状态, 终止状态, 以及 AddTransition 声明的目标状态
+func (m *TypedMachine[S, E, P]) AddStates(states ...S) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	for _, s := range states {
+		m.declared[s] = struct{}{}
+	}
+}
+
+// SetInitial 设置初始状态
+func (m *TypedMachine[S, E, P]) SetInitial(state S) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.initial = state
+	m.hasInitial = true
+}
+
+// Initial 返回初始状态, 未设置时 ok 为 false
+func (m *TypedMachine[S, E, P]) Initial() (state S, ok bool) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	return m.initial, m.hasInitial
+}
+
+// SetFinal 声明终止状态
+func (m *TypedMachine[S, E, P]) SetFinal(states ...S) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	for _, s := range states {
+		m.final[s] = struct{}{}
+	}
+}
+
+// IsFinal 判断是否为终止状态
+func (m *TypedMachine[S, E, P]) IsFinal(state S) bool {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var _, ok = m.final[state]
+	return ok
+}
+
+// known 已知状态集合, 调用方需持有锁
+func (m *TypedMachine[S, E, P]) known() map[S]struct{} {
+	var known = make(map[S]struct{})
+
+	for s := range m.declared {
+		known[s] = struct{}{}
+	}
+	for s := range m.final {
+		known[s] = struct{}{}
+	}
+	if m.hasInitial {
+		known[m.initial] = struct{}{}
+	}
+	for child, parent := range m.parent {
+		known[child] = struct{}{}
+		known[parent] = struct{}{}
+	}
+	for _, eh := range m.table {
+		for _, tr := range eh {
+			for _, s := range tr.to {
+				known[s] = struct{}{}
+			}
+		}
+	}
+	for _, tr := range m.any {
+		for _, s := range tr.to {
+			known[s] = struct{}{}
+		}
+	}
+
+	return known
+}
+
+// States 返回全部已知状态及定义了事件的状态, 按字符串形式排序
+func (m *TypedMachine[S, E, P]) States() []S {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var all = m.known()
+	for s := range m.table {
+		all[s] = struct{}{}
+	}
+
+	return sortStates(all)
+}
+
+func sortStates[S comparable](set map[S]struct{}) []S {
+	var list = make([]S, 0, len(set))
+	for s := range set {
+		list = append(list, s)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return fmt.Sprint(list[i]) < fmt.Sprint(list[j])
+	})
+	return list
+}
+
+// Validate 静态校验状态机定义, 存在问题时返回 *TypedValidationError
+//   - 未声明初始状态
+//   - 在未知状态上定义了事件
+//   - 从初始状态不可达的状态
+//   - 没有任何事件的非终止状态
+//
+// 状态继承父状态及任意状态的事件; 子状态可达时其祖先视为可达;
+// 未声明目标状态的事件视为可到达任意已知状态
+func (m *TypedMachine[S, E, P]) Validate() error {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var problems []Problem[S]
+	var known = m.known()
+
+	if !m.hasInitial {
+		problems = append(problems, Problem[S]{Kind: MissingInitial})
+	}
+
+	var from = make(map[S]struct{})
+	for s := range m.table {
+		from[s] = struct{}{}
+	}
+	for _, s := range sortStates(from) {
+		if _, ok := known[s]; !ok {
+			problems = append(problems, Problem[S]{Kind: UnknownState, State: s})
+		}
+	}
+
+	if m.hasInitial {
+		var reached = m.reachable(known)
+		for _, s := range sortStates(known) {
+			if _, ok := reached[s]; !ok {
+				problems = append(problems, Problem[S]{Kind: Unreachable, State: s})
+			}
+		}
+	}
+
+	for _, s := range sortStates(known) {
+		if _, ok := m.final[s]; ok {
+			continue
+		}
+		if !m.hasEvents(s) {
+			problems = append(problems, Problem[S]{Kind: DeadEnd, State: s})
+		}
+	}
+
+	if len(problems) > 0 {
+		return &TypedValidationError[S]{
+			Scheme:   m.name,
+			Problems: problems,
+		}
+	}
+	return nil
+}
+
+// reachable 从初始状态出发可到达的状态, 调用方需持有锁
+func (m *TypedMachine[S, E, P]) reachable(known map[S]struct{}) map[S]struct{} {
+	var reached = map[S]struct{}{m.initial: {}}
+	var queue = []S{m.initial}
+
+	for len(queue) > 0 {
+		var s = queue[0]
+		queue = queue[1:]
+
+		var trs []*typedTransition[S, P]
+		for _, a := range m.ancestors(s) {
+			for _, tr := range m.table[a] {
+				trs = append(trs, tr)
+			}
+		}
+		for _, tr := range m.any {
+			trs = append(trs, tr)
+		}
+
+		for _, tr := range trs {
+			var next = tr.to
+			if len(next) == 0 {
+				next = sortStates(known)
+			}
+
+			for _, n := range next {
+				if _, ok := reached[n]; !ok {
+					reached[n] = struct{}{}
+					queue = append(queue, n)
+				}
+			}
+		}
+	}
+
+	for s := range reached {
+		for _, a := range m.ancestors(s) {
+			reached[a] = struct{}{}
+		}
+	}
+
+	return reached
+}
+
+// AddStates 显式声明状态
+func (m *Machine) AddStates(s Scheme, states ...State) error {
+	return m.update(s, func(sc *scheme) error {
+		sc.AddStates(states...)
+		return nil
+	})
+}
+
+// SetInitial 设置初始状态
+func (m *Machine) SetInitial(s Scheme, state State) error {
+	return m.update(s, func(sc *scheme) error {
+		sc.SetInitial(state)
+		return nil
+	})
+}
+
+// SetFinal 声明终止状态
+func (m *Machine) SetFinal(s Scheme, states ...State) error {
+	return m.update(s, func(sc *scheme) error {
+		sc.SetFinal(states...)
+		return nil
+	})
+}
+
+// Validate 静态校验状态机定义, 存在问题时返回 *ValidationError
+func (m *Machine) Validate(s Scheme) error {
+	return m.update(s, func(sc *scheme) error {
+		return sc.Validate()
+	})
+}
+
+// Schemes 返回已注册的全部状态机名称, 按名称排序
+func (m *Machine) Schemes() []Scheme {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	var list = make([]Scheme, 0, len(m.schemes))
+	for s := range m.schemes {
+		list = append(list, s)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i] < list[j]
+	})
+	return list
+}
+
+// AddStates 显式声明状态 (默认Machine)
+func AddStates(s Scheme, states ...State) error {
+	return defaultMachine.AddStates(s, states...)
+}
+
+// SetInitial 设置初始状态 (默认Machine)
+func SetInitial(s Scheme, state State) error {
+	return defaultMachine.SetInitial(s, state)
+}
+
+// SetFinal 声明终止状态 (默认Machine)
+func SetFinal(s Scheme, states ...State) error {
+	return defaultMachine.SetFinal(s, states...)
+}
+
+// Validate 静态校验状态机定义 (默认Machine)
+func Validate(s Scheme) error {
+	return defaultMachine.Validate(s)
+}
+
+// Schemes 返回已注册的全部状态机名称 (默认Machine)
+func Schemes() []Scheme {
+	return defaultMachine.Schemes()
+}
@@ -0,0 +1,71 @@
+package fsmv1_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/alpha-abc/gokits/fsm/fsmv1"
+)
+
+func TestValidate(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	var err = m.Define("order").
+		Initial("created").
+		Final("closed").
+		States("archived").
+		On("created", "pay", to("paid"), "paid").
+		On("paid", "close", to("closed"), "closed").
+		On("paid", "ship", to("shipped"), "shipped").
+		On("cretaed", "cancel", to("closed"), "closed").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Validate("order")
+	var ve *fsmv1.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expect ValidationError, got %v", err)
+	}
+
+	var expect = []fsmv1.Problem[fsmv1.State]{
+		{Kind: fsmv1.UnknownState, State: "cretaed"},
+		{Kind: fsmv1.Unreachable, State: "archived"},
+		{Kind: fsmv1.DeadEnd, State: "archived"},
+		{Kind: fsmv1.DeadEnd, State: "shipped"},
+	}
+	if !reflect.DeepEqual(ve.Problems, expect) {
+		t.Fatalf("got %v, expect %v", ve.Problems, expect)
+	}
+}
+
+func TestValidateOK(t *testing.T) {
+	var m = fsmv1.NewMachine()
+
+	if err := m.Register("ticket"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.AddTransition("ticket", "open", "close", to("closed"), "closed"); err != nil {
+		t.Fatal(err)
+	}
+
+	var ve *fsmv1.ValidationError
+	if err := m.Validate("ticket"); !errors.As(err, &ve) || ve.Problems[0].Kind != fsmv1.MissingInitial {
+		t.Fatalf("expect missing initial, got %v", err)
+	}
+
+	if err := m.SetInitial("ticket", "open"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetFinal("ticket", "closed"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range m.Schemes() {
+		if err := m.Validate(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
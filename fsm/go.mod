@@ -1,3 +1,3 @@
 module github.com/alpha-abc/gokits/fsm
 
-go 1.16
+go 1.18
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alpha-abc/gokits/idserver/idserverv1"
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+var addr = flag.String("addr", "127.0.0.1:7100", "listening address")
+var nodeID = flag.Int64("node", 1, "snowflake node id")
+var idle = flag.Duration("idle", 5*time.Minute, "connection idle timeout, 0 means no limit")
+
+// go run ./cmd/idserver -addr 127.0.0.1:7100 -node 1
+func main() {
+	flag.Parse()
+
+	var node, err = snowflakev1.NewNode(*nodeID)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var server = idserverv1.NewServer(node)
+	server.SetIdleTimeout(*idle)
+
+	var sig = make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sig
+		var _ = server.Close()
+	}()
+
+	fmt.Println("run idserver:", *addr, "node:", *nodeID)
+	if err := server.ListenAndServe(*addr); err != nil && err != idserverv1.ErrClosed {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,210 @@
+package idserverv1
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/alpha-abc/gokits/packet/packetv1"
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+// Client ID生成服务的客户端, 本地缓存一批ID, 剩余数量不足时后台预取, 并发安全
+// 与 snowflakev1.Node 一样提供 Generate() (ID, error), 可直接替换
+type Client struct {
+	addr    string
+	batch   int           // 单次请求的ID数量
+	low     int           // 缓存剩余不超过此数量时后台预取
+	timeout time.Duration // 连接及单次请求超时
+	packet  *packetv1.Packet
+
+	mux      sync.Mutex
+	conn     net.Conn
+	reader   *bufio.Reader
+	buf      []snowflakev1.ID
+	fetching chan struct{} // 非 nil 表示正在预取, 预取结束时关闭
+	err      error         // 最近一次预取的错误
+	closed   bool
+}
+
+// NewClient 初始化, 每次向 {addr} 请求 {batch} 个ID, 首次生成ID时建立连接
+func NewClient(addr string, batch int) (*Client, error) {
+	if batch <= 0 || batch > MaxBatch {
+		return nil, fmt.Errorf("invalid batch %d, require [1 - %d]", batch, MaxBatch)
+	}
+
+	return &Client{
+		addr:    addr,
+		batch:   batch,
+		low:     batch / 2,
+		timeout: 3 * time.Second,
+		packet:  newPacket(),
+	}, nil
+}
+
+// SetLowWater 缓存剩余不超过 {n} 个ID时后台预取, 默认 batch/2, 需在生成ID之前调用
+func (c *Client) SetLowWater(n int) {
+	c.low = n
+}
+
+// SetTimeout 设置连接及单次请求超时, 默认3秒, 需在生成ID之前调用
+func (c *Client) SetTimeout(d time.Duration) {
+	c.timeout = d
+}
+
+// Generate 返回一个唯一的ID, 同一客户端返回的ID递增
+// 缓存为空时等待预取完成, 预取失败时返回错误, 下次调用重新请求
+func (c *Client) Generate() (snowflakev1.ID, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for len(c.buf) == 0 {
+		if c.closed {
+			return 0, ErrClosed
+		}
+
+		var done = c.prefetch()
+
+		c.mux.Unlock()
+		<-done
+		c.mux.Lock()
+
+		if len(c.buf) == 0 && c.err != nil {
+			var err = c.err
+			c.err = nil
+			return 0, err
+		}
+	}
+
+	var id = c.buf[0]
+	c.buf = c.buf[1:]
+
+	if len(c.buf) <= c.low {
+		c.prefetch()
+	}
+
+	return id, nil
+}
+
+// prefetch 启动后台预取, 已在预取时不重复启动; 需持有锁
+func (c *Client) prefetch() chan struct{} {
+	if c.fetching != nil {
+		return c.fetching
+	}
+
+	var done = make(chan struct{})
+	c.fetching = done
+	go c.fetch(done)
+	return done
+}
+
+// fetch 请求一批ID并放入缓存
+func (c *Client) fetch(done chan struct{}) {
+	var ids, err = c.request()
+
+	c.mux.Lock()
+	if !c.closed {
+		c.buf = append(c.buf, ids...)
+	}
+	c.err = err
+	c.fetching = nil
+	c.mux.Unlock()
+
+	close(done)
+}
+
+// request 发送请求并读取响应, 网络错误时断开连接, 下次请求重新连接
+func (c *Client) request() ([]snowflakev1.ID, error) {
+	var conn, reader, err = c.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	var _ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+	if err := writePacket(c.packet, conn, CmdGenerate, packetv1.EncodeUint64(4, uint64(c.batch))); err != nil {
+		c.disconnect(conn)
+		return nil, err
+	}
+
+	var _, command, data, uErr = c.packet.Unpack(reader)
+	if uErr != nil {
+		c.disconnect(conn)
+		return nil, uErr
+	}
+
+	switch command {
+	case CmdIDs:
+		return decodeIDs(data)
+	case CmdError:
+		return nil, &ServerError{Message: string(data)}
+	}
+
+	c.disconnect(conn)
+	return nil, fmt.Errorf("unexpected command %d", command)
+}
+
+// connect 返回当前连接, 没有连接时建立连接
+func (c *Client) connect() (net.Conn, *bufio.Reader, error) {
+	c.mux.Lock()
+	if c.closed {
+		c.mux.Unlock()
+		return nil, nil, ErrClosed
+	}
+	if c.conn != nil {
+		var conn, reader = c.conn, c.reader
+		c.mux.Unlock()
+		return conn, reader, nil
+	}
+	c.mux.Unlock()
+
+	// 同一时刻只有一个预取, 不会重复建立连接
+	var conn, err = net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	var reader = bufio.NewReader(conn)
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.closed {
+		var _ = conn.Close()
+		return nil, nil, ErrClosed
+	}
+	c.conn, c.reader = conn, reader
+	return conn, reader, nil
+}
+
+// disconnect 关闭出错的连接
+func (c *Client) disconnect(conn net.Conn) {
+	var _ = conn.Close()
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.conn == conn {
+		c.conn, c.reader = nil, nil
+	}
+}
+
+// Close 关闭连接并丢弃缓存的ID, 之后 Generate 返回 ErrClosed
+func (c *Client) Close() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	c.buf = nil
+
+	if c.conn != nil {
+		var err = c.conn.Close()
+		c.conn, c.reader = nil, nil
+		return err
+	}
+	return nil
+}
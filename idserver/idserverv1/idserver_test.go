@@ -0,0 +1,125 @@
+package idserverv1_test
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/alpha-abc/gokits/idserver/idserverv1"
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+// startServer 在本地回环地址启动服务
+func startServer(t *testing.T, nodeID int64) (*idserverv1.Server, string) {
+	var node, err = snowflakev1.NewNode(nodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var server = idserverv1.NewServer(node)
+	go func() {
+		var _ = server.Serve(l)
+	}()
+
+	return server, l.Addr().String()
+}
+
+func TestClientGenerate(t *testing.T) {
+	var server, addr = startServer(t, 7)
+	defer func() { var _ = server.Close() }()
+
+	var client, err = idserverv1.NewClient(addr, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { var _ = client.Close() }()
+
+	// 单协程内递增
+	var prev snowflakev1.ID
+	for i := 0; i < 1000; i++ {
+		var id, err = client.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= prev {
+			t.Fatalf("id %d not greater than %d", id, prev)
+		}
+		if id.Decode(snowflakev1.DefaultLayout()).NodeID != 7 {
+			t.Fatalf("node id of %d", id)
+		}
+		prev = id
+	}
+
+	// 并发唯一
+	var mux sync.Mutex
+	var seen = make(map[snowflakev1.ID]struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				var id, err = client.Generate()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				mux.Lock()
+				if _, ok := seen[id]; ok {
+					t.Errorf("duplicate id %d", id)
+				}
+				seen[id] = struct{}{}
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClientServerDown(t *testing.T) {
+	var server, addr = startServer(t, 1)
+
+	var client, _ = idserverv1.NewClient(addr, 10)
+	client.SetLowWater(0)
+	defer func() { var _ = client.Close() }()
+
+	if _, err := client.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 缓存用尽后返回错误
+	var err error
+	for i := 0; i < 20 && err == nil; i++ {
+		_, err = client.Generate()
+	}
+	if err == nil {
+		t.Fatal("expect error after server closed")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Generate(); !errors.Is(err, idserverv1.ErrClosed) {
+		t.Fatalf("expect ErrClosed, got %v", err)
+	}
+}
+
+func TestInvalidBatch(t *testing.T) {
+	if _, err := idserverv1.NewClient("127.0.0.1:0", 0); err == nil {
+		t.Fatal("expect error")
+	}
+	if _, err := idserverv1.NewClient("127.0.0.1:0", idserverv1.MaxBatch+1); err == nil {
+		t.Fatal("expect error")
+	}
+}
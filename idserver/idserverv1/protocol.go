@@ -0,0 +1,78 @@
+package idserverv1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alpha-abc/gokits/packet/packetv1"
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+// Version 协议版本
+const Version = 1
+
+// 命令
+const (
+	CmdGenerate uint64 = 1 // 请求: 数据为 4字节 请求数量
+	CmdIDs      uint64 = 2 // 响应: 数据为 N个 8字节 ID
+	CmdError    uint64 = 3 // 响应: 数据为 错误信息
+)
+
+// MaxBatch 单次请求的最大ID数量, 保证响应不超过一个包 (数据长度2字节)
+const MaxBatch = (1<<16 - 1) / 8
+
+// ErrClosed 服务端或客户端已关闭
+var ErrClosed = errors.New("idserver closed")
+
+// ServerError 服务端返回的错误
+type ServerError struct {
+	Message string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("idserver error: %s", e.Message)
+}
+
+// newPacket 包头: 版本号1字节, 命令1字节, 数据长度2字节
+func newPacket() *packetv1.Packet {
+	var p, _ = packetv1.NewPacket(1, 1, 2)
+	return p
+}
+
+// writePacket 封包并写入
+func writePacket(p *packetv1.Packet, w io.Writer, command uint64, data []byte) error {
+	var bss, err = p.Pack(Version, command, data)
+	if err != nil {
+		return err
+	}
+
+	for _, bs := range bss {
+		if _, err := w.Write(bs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeIDs 每个ID编码为8字节
+func encodeIDs(ids []snowflakev1.ID) []byte {
+	var bs = make([]byte, 0, len(ids)*8)
+	for _, id := range ids {
+		bs = append(bs, packetv1.EncodeUint64(8, uint64(id))...)
+	}
+	return bs
+}
+
+// decodeIDs 解码 encodeIDs 的结果
+func decodeIDs(bs []byte) ([]snowflakev1.ID, error) {
+	if len(bs)%8 != 0 {
+		return nil, fmt.Errorf("invalid ids data length %d", len(bs))
+	}
+
+	var ids = make([]snowflakev1.ID, 0, len(bs)/8)
+	for i := 0; i < len(bs); i += 8 {
+		ids = append(ids, snowflakev1.ID(packetv1.DecodeUint64(bs[i:i+8])))
+	}
+	return ids, nil
+}
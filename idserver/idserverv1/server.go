@@ -0,0 +1,208 @@
+package idserverv1
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/alpha-abc/gokits/packet/packetv1"
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+// Server ID生成服务, 每个连接上按请求顺序返回一批ID, 并发安全
+type Server struct {
+	node   *snowflakev1.Node
+	packet *packetv1.Packet
+
+	idleTimeout time.Duration // 连接空闲超时, 0 表示不限制
+
+	mux       sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer 初始化, 使用 {node} 生成ID
+func NewServer(node *snowflakev1.Node) *Server {
+	return &Server{
+		node:   node,
+		packet: newPacket(),
+
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// SetIdleTimeout 设置连接空闲超时, 超时未收到请求时关闭连接
+func (s *Server) SetIdleTimeout(d time.Duration) {
+	s.idleTimeout = d
+}
+
+// ListenAndServe 监听 {addr} 并处理连接, 直到 Close
+func (s *Server) ListenAndServe(addr string) error {
+	var l, err = net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve 在 {l} 上处理连接, 直到 Close; Close 后返回 ErrClosed
+func (s *Server) Serve(l net.Listener) error {
+	s.mux.Lock()
+	if s.closed {
+		s.mux.Unlock()
+		var _ = l.Close()
+		return ErrClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mux.Unlock()
+
+	defer func() {
+		s.mux.Lock()
+		delete(s.listeners, l)
+		s.mux.Unlock()
+	}()
+
+	for {
+		var conn, err = l.Accept()
+		if err != nil {
+			s.mux.Lock()
+			var closed = s.closed
+			s.mux.Unlock()
+
+			if closed {
+				return ErrClosed
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			var _ = conn.Close()
+			return ErrClosed
+		}
+
+		go s.handle(conn)
+	}
+}
+
+// track 记录连接, 已关闭时返回 false
+func (s *Server) track(conn net.Conn) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+// handle 处理单个连接上的请求
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		var _ = conn.Close()
+
+		s.mux.Lock()
+		delete(s.conns, conn)
+		s.mux.Unlock()
+
+		s.wg.Done()
+	}()
+
+	var reader = bufio.NewReader(conn)
+	var writer = bufio.NewWriter(conn)
+
+	for {
+		if s.idleTimeout > 0 {
+			var _ = conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		}
+
+		var version, command, data, err = s.packet.Unpack(reader)
+		if err != nil {
+			return
+		}
+
+		var ids []snowflakev1.ID
+		if version != Version {
+			err = fmt.Errorf("unsupported version %d", version)
+		} else if command != CmdGenerate {
+			err = fmt.Errorf("unsupported command %d", command)
+		} else {
+			ids, err = s.generate(data)
+		}
+
+		if err != nil {
+			err = writePacket(s.packet, writer, CmdError, []byte(err.Error()))
+		} else {
+			err = writePacket(s.packet, writer, CmdIDs, encodeIDs(ids))
+		}
+		if err != nil {
+			return
+		}
+
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// generate 解析请求数量并生成ID, 按节点单个时间单位的序列号数量分批调用 GenerateN
+func (s *Server) generate(data []byte) ([]snowflakev1.ID, error) {
+	if len(data) != 4 {
+		return nil, fmt.Errorf("invalid generate data length %d", len(data))
+	}
+
+	var count = int(packetv1.DecodeUint64(data))
+	if count <= 0 || count > MaxBatch {
+		return nil, fmt.Errorf("invalid count %d, require [1 - %d]", count, MaxBatch)
+	}
+
+	var step = int(s.node.Layout().MaxSequence() + 1)
+	var ids = make([]snowflakev1.ID, 0, count)
+
+	for len(ids) < count {
+		var n = count - len(ids)
+		if n > step {
+			n = step
+		}
+
+		var first, err = s.node.GenerateN(n)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			ids = append(ids, first+snowflakev1.ID(i))
+		}
+	}
+
+	return ids, nil
+}
+
+// Close 关闭全部监听及连接, 并等待连接处理结束
+func (s *Server) Close() error {
+	s.mux.Lock()
+	if s.closed {
+		s.mux.Unlock()
+		return nil
+	}
+	s.closed = true
+
+	var err error
+	for l := range s.listeners {
+		if e := l.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	for conn := range s.conns {
+		var _ = conn.Close()
+	}
+	s.mux.Unlock()
+
+	s.wg.Wait()
+	return err
+}
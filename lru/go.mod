@@ -1,3 +1,3 @@
 module github.com/alpha-abc/gokits/lru
 
-go 1.14
+go 1.18
@@ -0,0 +1,211 @@
+package lruv1
+
+import "container/list"
+
+// ARC 自适应替换缓存 (Adaptive Replacement Cache), 最多保存 capacity 个元素, 并发不安全
+//
+// t1: 最近只访问过一次的元素, t2: 最近访问过至少两次的元素;
+// b1/b2: 从 t1/t2 淘汰的key (幽灵记录, 不保存值), 各自命中时调整 t1 的目标大小 p,
+// 使缓存在偏向最近访问与偏向访问频率之间自适应, 一次性的扫描只会冲刷 t1
+//
+// 见 Megiddo & Modha, "ARC: A Self-Tuning, Low Overhead Replacement Cache", FAST 2003
+type ARC[K comparable, V any] struct {
+	capacity int
+	p        int // t1 的目标大小
+
+	t1, t2, b1, b2 *list.List // 队首为最近访问
+	items          map[K]*arcEntity[K, V]
+
+	onDelete TypedDeleteFunc[K, V]
+}
+
+type arcEntity[K comparable, V any] struct {
+	key K
+	val V
+
+	ll  *list.List // 所在队列
+	ele *list.Element
+}
+
+// NewARC 初始化, {capacity} 小于1时按1处理
+func NewARC[K comparable, V any](capacity int, onDelete TypedDeleteFunc[K, V]) *ARC[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &ARC[K, V]{
+		capacity: capacity,
+		t1:       list.New(),
+		t2:       list.New(),
+		b1:       list.New(),
+		b2:       list.New(),
+		items:    make(map[K]*arcEntity[K, V]),
+		onDelete: onDelete,
+	}
+}
+
+// Get 根据key获取元素, 命中时移到 t2
+func (c *ARC[K, V]) Get(key K) (V, bool) {
+	if e, ok := c.items[key]; ok && c.resident(e) {
+		c.move(e, c.t2)
+		return e.val, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Add 添加元素
+func (c *ARC[K, V]) Add(key K, value V) {
+	var e, ok = c.items[key]
+
+	switch {
+	case ok && c.resident(e):
+		e.val = value
+		c.move(e, c.t2)
+		return
+
+	case ok && e.ll == c.b1:
+		// 最近淘汰的一次访问元素再次被访问, 增大 t1 的目标大小
+		c.p = minInt(c.capacity, c.p+maxInt(c.b2.Len()/c.b1.Len(), 1))
+		c.replace(false)
+		e.val = value
+		c.move(e, c.t2)
+		return
+
+	case ok && e.ll == c.b2:
+		// 最近淘汰的多次访问元素再次被访问, 减小 t1 的目标大小
+		c.p = maxInt(0, c.p-maxInt(c.b1.Len()/c.b2.Len(), 1))
+		c.replace(true)
+		e.val = value
+		c.move(e, c.t2)
+		return
+	}
+
+	var l1 = c.t1.Len() + c.b1.Len()
+	var total = l1 + c.t2.Len() + c.b2.Len()
+
+	if l1 >= c.capacity {
+		if c.t1.Len() < c.capacity {
+			c.drop(c.b1.Back())
+			c.replace(false)
+		} else {
+			c.evict(c.t1.Back(), nil)
+		}
+	} else if total >= c.capacity {
+		if total >= 2*c.capacity {
+			c.drop(c.b2.Back())
+		}
+		c.replace(false)
+	}
+
+	e = &arcEntity[K, V]{key: key, val: value, ll: c.t1}
+	e.ele = c.t1.PushFront(e)
+	c.items[key] = e
+}
+
+// Del 根据key删除元素, 同时清除幽灵记录
+func (c *ARC[K, V]) Del(key K) bool {
+	var e, ok = c.items[key]
+	if !ok {
+		return false
+	}
+
+	if !c.resident(e) {
+		c.drop(e.ele)
+		return false
+	}
+
+	e.ll.Remove(e.ele)
+	delete(c.items, key)
+
+	if c.onDelete != nil {
+		c.onDelete(e.key, e.val, EvictExplicit)
+	}
+	return true
+}
+
+// Len 元素个数, 不含幽灵记录
+func (c *ARC[K, V]) Len() int {
+	return c.t1.Len() + c.t2.Len()
+}
+
+// resident 是否保存了值 (在 t1 或 t2 中)
+func (c *ARC[K, V]) resident(e *arcEntity[K, V]) bool {
+	return e.ll == c.t1 || e.ll == c.t2
+}
+
+// replace 缓存已满时, 按目标大小 p 从 t1 或 t2 淘汰一个元素到对应的幽灵队列
+// {inB2} 表示本次访问命中了 b2
+func (c *ARC[K, V]) replace(inB2 bool) {
+	if c.t1.Len()+c.t2.Len() < c.capacity {
+		return
+	}
+
+	var t1Len = c.t1.Len()
+	if t1Len > 0 && (t1Len > c.p || (inB2 && t1Len == c.p) || c.t2.Len() == 0) {
+		c.evict(c.t1.Back(), c.b1)
+	} else {
+		c.evict(c.t2.Back(), c.b2)
+	}
+}
+
+// evict 淘汰元素, {ghost} 不为 nil 时保留key到幽灵队列
+func (c *ARC[K, V]) evict(ele *list.Element, ghost *list.List) {
+	if ele == nil {
+		return
+	}
+
+	var e = ele.Value.(*arcEntity[K, V])
+	var val = e.val
+
+	if ghost != nil {
+		var zero V
+		e.val = zero
+		c.move(e, ghost)
+	} else {
+		e.ll.Remove(ele)
+		delete(c.items, e.key)
+	}
+
+	if c.onDelete != nil {
+		c.onDelete(e.key, val, EvictCapacity)
+	}
+}
+
+// drop 删除幽灵记录
+func (c *ARC[K, V]) drop(ele *list.Element) {
+	if ele == nil {
+		return
+	}
+
+	var e = ele.Value.(*arcEntity[K, V])
+	e.ll.Remove(ele)
+	delete(c.items, e.key)
+}
+
+// move 将元素移到 {to} 队首
+func (c *ARC[K, V]) move(e *arcEntity[K, V], to *list.List) {
+	if e.ll == to {
+		to.MoveToFront(e.ele)
+		return
+	}
+
+	e.ll.Remove(e.ele)
+	e.ll = to
+	e.ele = to.PushFront(e)
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+package lruv1_test
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+// mutexCache 以单个互斥锁包装 Cache, 作为对比
+type mutexCache struct {
+	mux   sync.Mutex
+	cache *lruv1.Cache
+}
+
+func (c *mutexCache) Get(key string) (lruv1.Value, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.cache.Get(key)
+}
+
+func (c *mutexCache) Add(key string, value lruv1.Value) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.cache.Add(key, value)
+}
+
+type benchCache interface {
+	Get(key string) (lruv1.Value, bool)
+	Add(key string, value lruv1.Value)
+}
+
+const benchKeys = 1 << 14
+
+var keys = func() []string {
+	var ks = make([]string, benchKeys)
+	for i := range ks {
+		ks[i] = "key-" + strconv.Itoa(i)
+	}
+	return ks
+}()
+
+// benchParallel 90% Get, 10% Add, 内存限制为全部key的一半
+func benchParallel(b *testing.B, cache benchCache) {
+	for i := 0; i < benchKeys/2; i++ {
+		cache.Add(keys[i], String("value"))
+	}
+
+	var seed int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i = int(atomic.AddInt64(&seed, 7919))
+		for pb.Next() {
+			i++
+			var key = keys[(i*31)&(benchKeys-1)]
+			if i%10 == 0 {
+				cache.Add(key, String("value"))
+			} else {
+				cache.Get(key)
+			}
+		}
+	})
+}
+
+const benchBytes = benchKeys / 2 * 14
+
+func BenchmarkMutexParallel(b *testing.B) {
+	benchParallel(b, &mutexCache{cache: lruv1.New(benchBytes, nil)})
+}
+
+func BenchmarkShardedParallel(b *testing.B) {
+	benchParallel(b, lruv1.NewSharded(lruv1.DefaultShards, benchBytes, nil))
+}
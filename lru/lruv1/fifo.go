@@ -0,0 +1,77 @@
+package lruv1
+
+import "container/list"
+
+// FIFO 先进先出缓存, 最多保存 capacity 个元素 (0 表示不限制), 访问不影响淘汰顺序, 并发不安全
+type FIFO[K comparable, V any] struct {
+	capacity int
+
+	items map[K]*list.Element
+	ll    *list.List // 队首为最新加入
+
+	onDelete TypedDeleteFunc[K, V]
+}
+
+type fifoEntity[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// NewFIFO 初始化
+func NewFIFO[K comparable, V any](capacity int, onDelete TypedDeleteFunc[K, V]) *FIFO[K, V] {
+	return &FIFO[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		ll:       list.New(),
+		onDelete: onDelete,
+	}
+}
+
+// Get 根据key获取元素
+func (c *FIFO[K, V]) Get(key K) (V, bool) {
+	if ele, ok := c.items[key]; ok {
+		return ele.Value.(*fifoEntity[K, V]).val, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Add 添加元素, 已存在时只更新值, 不改变淘汰顺序
+func (c *FIFO[K, V]) Add(key K, value V) {
+	if ele, ok := c.items[key]; ok {
+		ele.Value.(*fifoEntity[K, V]).val = value
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&fifoEntity[K, V]{key: key, val: value})
+
+	for c.capacity > 0 && c.ll.Len() > c.capacity {
+		c.remove(c.ll.Back(), EvictCapacity)
+	}
+}
+
+// Del 根据key删除元素
+func (c *FIFO[K, V]) Del(key K) bool {
+	if ele, ok := c.items[key]; ok {
+		c.remove(ele, EvictExplicit)
+		return true
+	}
+	return false
+}
+
+// Len 元素个数
+func (c *FIFO[K, V]) Len() int {
+	return c.ll.Len()
+}
+
+func (c *FIFO[K, V]) remove(ele *list.Element, reason EvictReason) {
+	c.ll.Remove(ele)
+
+	var e = ele.Value.(*fifoEntity[K, V])
+	delete(c.items, e.key)
+
+	if c.onDelete != nil {
+		c.onDelete(e.key, e.val, reason)
+	}
+}
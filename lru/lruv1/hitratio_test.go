@@ -0,0 +1,79 @@
+package lruv1_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+const (
+	traceLen      = 200000
+	traceKeys     = 10000
+	traceCapacity = 500
+)
+
+// zipfTrace Zipf 分布的访问序列
+func zipfTrace() []int {
+	var r = rand.New(rand.NewSource(1))
+	var z = rand.NewZipf(r, 1.01, 1, traceKeys-1)
+
+	var trace = make([]int, traceLen)
+	for i := range trace {
+		trace[i] = int(z.Uint64())
+	}
+	return trace
+}
+
+// scanTrace Zipf 访问中周期性夹杂一次性的顺序扫描
+func scanTrace() []int {
+	var r = rand.New(rand.NewSource(2))
+	var z = rand.NewZipf(r, 1.01, 1, traceKeys-1)
+
+	var trace = make([]int, 0, traceLen)
+	var next = traceKeys
+	for len(trace) < traceLen {
+		for i := 0; i < 2000 && len(trace) < traceLen; i++ {
+			trace = append(trace, int(z.Uint64()))
+		}
+		for i := 0; i < 1000 && len(trace) < traceLen; i++ {
+			trace = append(trace, next)
+			next++
+		}
+	}
+	return trace
+}
+
+// hitRatio 按访问序列读取缓存, 未命中时添加, 返回命中率
+func hitRatio(cache lruv1.Interface[int, int], trace []int) float64 {
+	var hits int
+	for _, key := range trace {
+		if _, ok := cache.Get(key); ok {
+			hits++
+		} else {
+			cache.Add(key, key)
+		}
+	}
+	return float64(hits) / float64(len(trace))
+}
+
+func benchHitRatio(b *testing.B, trace []int) {
+	for _, p := range policies {
+		b.Run(p.String(), func(b *testing.B) {
+			var ratio float64
+			for i := 0; i < b.N; i++ {
+				var cache, _ = lruv1.NewPolicy[int, int](p, traceCapacity, nil)
+				ratio = hitRatio(cache, trace)
+			}
+			b.ReportMetric(ratio*100, "hit%")
+		})
+	}
+}
+
+func BenchmarkHitRatioZipf(b *testing.B) {
+	benchHitRatio(b, zipfTrace())
+}
+
+func BenchmarkHitRatioScan(b *testing.B) {
+	benchHitRatio(b, scanTrace())
+}
@@ -0,0 +1,125 @@
+package lruv1
+
+import "container/list"
+
+// LFU 最少使用缓存, 最多保存 capacity 个元素 (0 表示不限制), 并发不安全
+// 相同访问次数的元素放在同一个频率桶中, 桶按访问次数递增排列, Get/Add/淘汰 均为 O(1);
+// 淘汰访问次数最少的元素, 次数相同时淘汰其中最久未访问的
+type LFU[K comparable, V any] struct {
+	capacity int
+
+	items   map[K]*list.Element // 元素在所属桶 entities 中的位置
+	buckets *list.List          // 频率桶, 队首访问次数最少
+
+	onDelete TypedDeleteFunc[K, V]
+}
+
+type lfuBucket struct {
+	freq     uint64
+	entities *list.List // 队首为最近访问
+}
+
+type lfuEntity[K comparable, V any] struct {
+	key    K
+	val    V
+	bucket *list.Element // 所属频率桶
+}
+
+// NewLFU 初始化
+func NewLFU[K comparable, V any](capacity int, onDelete TypedDeleteFunc[K, V]) *LFU[K, V] {
+	return &LFU[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		buckets:  list.New(),
+		onDelete: onDelete,
+	}
+}
+
+// Get 根据key获取元素, 访问次数加1
+func (c *LFU[K, V]) Get(key K) (V, bool) {
+	if ele, ok := c.items[key]; ok {
+		c.touch(ele)
+		return ele.Value.(*lfuEntity[K, V]).val, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Add 添加元素, 已存在时更新值并且访问次数加1
+func (c *LFU[K, V]) Add(key K, value V) {
+	if ele, ok := c.items[key]; ok {
+		ele.Value.(*lfuEntity[K, V]).val = value
+		c.touch(ele)
+		return
+	}
+
+	if c.capacity > 0 && len(c.items) >= c.capacity {
+		c.evict()
+	}
+
+	// 新元素放入访问次数为1的桶
+	var front = c.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).freq != 1 {
+		front = c.buckets.PushFront(&lfuBucket{freq: 1, entities: list.New()})
+	}
+
+	var e = &lfuEntity[K, V]{key: key, val: value, bucket: front}
+	c.items[key] = front.Value.(*lfuBucket).entities.PushFront(e)
+}
+
+// Del 根据key删除元素
+func (c *LFU[K, V]) Del(key K) bool {
+	if ele, ok := c.items[key]; ok {
+		c.remove(ele, EvictExplicit)
+		return true
+	}
+	return false
+}
+
+// Len 元素个数
+func (c *LFU[K, V]) Len() int {
+	return len(c.items)
+}
+
+// touch 将元素移到访问次数加1的桶中
+func (c *LFU[K, V]) touch(ele *list.Element) {
+	var e = ele.Value.(*lfuEntity[K, V])
+	var curr = e.bucket
+	var b = curr.Value.(*lfuBucket)
+
+	var next = curr.Next()
+	if next == nil || next.Value.(*lfuBucket).freq != b.freq+1 {
+		next = c.buckets.InsertAfter(&lfuBucket{freq: b.freq + 1, entities: list.New()}, curr)
+	}
+
+	b.entities.Remove(ele)
+	if b.entities.Len() == 0 {
+		c.buckets.Remove(curr)
+	}
+
+	e.bucket = next
+	c.items[e.key] = next.Value.(*lfuBucket).entities.PushFront(e)
+}
+
+// evict 淘汰访问次数最少的桶中最久未访问的元素
+func (c *LFU[K, V]) evict() {
+	if front := c.buckets.Front(); front != nil {
+		c.remove(front.Value.(*lfuBucket).entities.Back(), EvictCapacity)
+	}
+}
+
+func (c *LFU[K, V]) remove(ele *list.Element, reason EvictReason) {
+	var e = ele.Value.(*lfuEntity[K, V])
+	var b = e.bucket.Value.(*lfuBucket)
+
+	b.entities.Remove(ele)
+	if b.entities.Len() == 0 {
+		c.buckets.Remove(e.bucket)
+	}
+	delete(c.items, e.key)
+
+	if c.onDelete != nil {
+		c.onDelete(e.key, e.val, reason)
+	}
+}
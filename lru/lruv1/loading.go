@@ -0,0 +1,204 @@
+package lruv1
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoaderFunc 缓存未命中时加载元素
+type LoaderFunc[K comparable, V any] func(key K) (V, error)
+
+// LoadingCache 带加载功能的并发安全缓存, 以一把锁保护 TypedCache
+//
+// GetOrLoad: 同一key并发未命中时只调用一次加载方法, 其余调用等待并共享结果;
+// 可选缓存加载错误 (SetNegativeTTL), 在错误过期前直接返回该错误;
+// 可选提前刷新 (SetRefreshAhead), 命中的元素剩余有效期不足时在后台重新加载, 期间仍返回旧值
+type LoadingCache[K comparable, V any] struct {
+	mux   sync.Mutex
+	cache *TypedCache[K, V]
+
+	errs    *TypedCache[K, error] // 加载错误, nil 表示不缓存
+	refresh time.Duration         // 剩余有效期不超过该值时提前刷新, 0 表示不刷新
+
+	calls map[K]*loadCall[V] // 正在进行的加载, Add/Del 时移除, 结果不再保存
+}
+
+// loadCall 一次正在进行的加载
+type loadCall[V any] struct {
+	wg  sync.WaitGroup
+	val V
+	err error
+}
+
+// NewLoading 以 {cache} 初始化, 之后只能通过 LoadingCache 访问 {cache}
+// 加载的元素使用 cache 的默认过期时长 (TypedCache.SetDefaultTTL)
+func NewLoading[K comparable, V any](cache *TypedCache[K, V]) *LoadingCache[K, V] {
+	return &LoadingCache[K, V]{
+		cache: cache,
+		calls: make(map[K]*loadCall[V]),
+	}
+}
+
+// SetNegativeTTL 缓存加载错误 {ttl}, 最多缓存 {max} 个key的错误 (0 表示不限制); ttl <= 0 时不缓存错误
+func (c *LoadingCache[K, V]) SetNegativeTTL(ttl time.Duration, max int) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if ttl <= 0 {
+		c.errs = nil
+		return
+	}
+
+	c.errs = NewTyped[K, error](int64(max), nil)
+	c.errs.SetClock(c.cache.clock)
+	c.errs.SetDefaultTTL(ttl)
+}
+
+// SetRefreshAhead 命中的元素剩余有效期不超过 {d} 时在后台重新加载, 0 表示不提前刷新
+// 只对设置了过期时间的元素生效, 刷新失败时保留旧值直到过期
+func (c *LoadingCache[K, V]) SetRefreshAhead(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.refresh = d
+}
+
+// GetOrLoad 获取元素, 未命中时调用 {loader} 加载并添加到缓存
+func (c *LoadingCache[K, V]) GetOrLoad(key K, loader LoaderFunc[K, V]) (V, error) {
+	c.mux.Lock()
+
+	if v, expire, ok := c.cache.getExpire(key); ok {
+		if c.refresh > 0 && !expire.IsZero() && expire.Sub(c.cache.clock.Now()) <= c.refresh {
+			if _, loading := c.calls[key]; !loading {
+				var call = c.start(key)
+				go c.load(key, loader, call, true)
+			}
+		}
+
+		c.mux.Unlock()
+		return v, nil
+	}
+
+	if c.errs != nil {
+		if err, ok := c.errs.Get(key); ok {
+			c.mux.Unlock()
+
+			var zero V
+			return zero, err
+		}
+	}
+
+	if call, ok := c.calls[key]; ok {
+		c.mux.Unlock()
+
+		call.wg.Wait()
+		return call.val, call.err
+	}
+
+	var call = c.start(key)
+	c.mux.Unlock()
+
+	c.load(key, loader, call, false)
+	return call.val, call.err
+}
+
+// start 登记一次加载; 需持有锁
+func (c *LoadingCache[K, V]) start(key K) *loadCall[V] {
+	var call = &loadCall[V]{}
+	call.wg.Add(1)
+	c.calls[key] = call
+	return call
+}
+
+// load 调用 {loader} 并保存结果, {refresh} 表示后台刷新, 刷新失败时不缓存错误
+// loader panic 时等待者收到错误, panic 继续向上抛出; 后台刷新时没有调用者可以 recover, 丢弃 panic 并保留旧值
+func (c *LoadingCache[K, V]) load(key K, loader LoaderFunc[K, V], call *loadCall[V], refresh bool) {
+	var finished bool
+	defer func() {
+		if !finished {
+			call.err = fmt.Errorf("load key(%v) panic: %v", key, recover())
+			c.finish(key, call, refresh)
+			if !refresh {
+				panic(call.err)
+			}
+		}
+	}()
+
+	call.val, call.err = loader(key)
+	finished = true
+
+	c.finish(key, call, refresh)
+}
+
+// finish 保存加载结果并唤醒等待者
+// 加载期间该key被 Add/Del 时 (已不在 calls 中), 加载结果已过时, 只返回给等待者, 不保存
+func (c *LoadingCache[K, V]) finish(key K, call *loadCall[V], refresh bool) {
+	c.mux.Lock()
+
+	if c.calls[key] == call {
+		delete(c.calls, key)
+
+		if call.err == nil {
+			c.cache.Add(key, call.val)
+			if c.errs != nil {
+				c.errs.Del(key)
+			}
+		} else if c.errs != nil && !refresh {
+			c.errs.Add(key, call.err)
+		}
+	}
+
+	c.mux.Unlock()
+
+	call.wg.Done()
+}
+
+// Get 根据key获取元素, 不加载
+func (c *LoadingCache[K, V]) Get(key K) (V, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.cache.Get(key)
+}
+
+// Add 添加元素, 同时清除该key缓存的加载错误; 正在进行的加载结果不再保存, 不会覆盖该值
+func (c *LoadingCache[K, V]) Add(key K, value V) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.calls, key)
+	c.cache.Add(key, value)
+	if c.errs != nil {
+		c.errs.Del(key)
+	}
+}
+
+// Del 删除元素及缓存的加载错误; 正在进行的加载结果不再保存, 之后的 GetOrLoad 重新加载
+func (c *LoadingCache[K, V]) Del(key K) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	delete(c.calls, key)
+
+	if c.errs != nil {
+		c.errs.Del(key)
+	}
+	return c.cache.Del(key)
+}
+
+// Len 元素个数
+func (c *LoadingCache[K, V]) Len() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.cache.Len()
+}
+
+// Stats 统计快照, 不含缓存的加载错误
+func (c *LoadingCache[K, V]) Stats() Stats {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.cache.Stats()
+}
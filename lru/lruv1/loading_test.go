@@ -0,0 +1,222 @@
+package lruv1_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	var cache = lruv1.NewLoading(lruv1.NewTyped[string, int](0, nil))
+
+	var calls int32
+	var release = make(chan struct{})
+	var loader = func(key string) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return len(key), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v, err = cache.GetOrLoad("hello", loader)
+			if err != nil || v != 5 {
+				t.Errorf("get or load: %d %v", v, err)
+			}
+		}()
+	}
+
+	// 等待全部协程进入等待
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("loader called %d times", calls)
+	}
+	if v, ok := cache.Get("hello"); !ok || v != 5 {
+		t.Fatalf("cached: %d %v", v, ok)
+	}
+}
+
+func TestGetOrLoadNegative(t *testing.T) {
+	var clock = newFakeClock()
+
+	var typed = lruv1.NewTyped[string, int](0, nil)
+	typed.SetClock(clock)
+
+	var cache = lruv1.NewLoading(typed)
+	cache.SetNegativeTTL(time.Second, 100)
+
+	var errDB = errors.New("db down")
+	var calls int
+	var fail = true
+	var loader = func(key string) (int, error) {
+		calls++
+		if fail {
+			return 0, errDB
+		}
+		return 1, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetOrLoad("k", loader); !errors.Is(err, errDB) {
+			t.Fatalf("expect db error, got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("loader called %d times within negative ttl", calls)
+	}
+
+	fail = false
+	clock.Add(time.Second)
+
+	if v, err := cache.GetOrLoad("k", loader); err != nil || v != 1 {
+		t.Fatalf("after negative ttl: %d %v", v, err)
+	}
+	if calls != 2 {
+		t.Fatalf("loader called %d times", calls)
+	}
+}
+
+func TestGetOrLoadRefreshAhead(t *testing.T) {
+	var clock = newFakeClock()
+
+	var typed = lruv1.NewTyped[string, int](0, nil)
+	typed.SetClock(clock)
+	typed.SetDefaultTTL(10 * time.Second)
+
+	var cache = lruv1.NewLoading(typed)
+	cache.SetRefreshAhead(3 * time.Second)
+
+	var version int32
+	var loader = func(key string) (int, error) {
+		return int(atomic.AddInt32(&version, 1)), nil
+	}
+
+	if v, _ := cache.GetOrLoad("k", loader); v != 1 {
+		t.Fatalf("first load %d", v)
+	}
+
+	// 未到刷新时间
+	clock.Add(6 * time.Second)
+	if v, _ := cache.GetOrLoad("k", loader); v != 1 || atomic.LoadInt32(&version) != 1 {
+		t.Fatalf("refreshed too early: %d", v)
+	}
+
+	// 进入刷新窗口, 返回旧值并在后台刷新
+	clock.Add(2 * time.Second)
+	if v, _ := cache.GetOrLoad("k", loader); v != 1 {
+		t.Fatalf("refresh should return old value, got %d", v)
+	}
+
+	var deadline = time.Now().Add(time.Second)
+	for {
+		if v, _ := cache.Get("k"); v == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 刷新后重新计算过期时间
+	clock.Add(5 * time.Second)
+	if v, ok := cache.Get("k"); !ok || v != 2 {
+		t.Fatalf("refreshed value expired: %d %v", v, ok)
+	}
+}
+
+func TestGetOrLoadRefreshPanic(t *testing.T) {
+	var clock = newFakeClock()
+
+	var typed = lruv1.NewTyped[string, int](0, nil)
+	typed.SetClock(clock)
+	typed.SetDefaultTTL(10 * time.Second)
+
+	var cache = lruv1.NewLoading(typed)
+	cache.SetRefreshAhead(3 * time.Second)
+
+	var calls int32
+	var loader = func(key string) (int, error) {
+		var n = atomic.AddInt32(&calls, 1)
+		if n == 2 {
+			panic("refresh failed")
+		}
+		return int(n), nil
+	}
+
+	if v, _ := cache.GetOrLoad("k", loader); v != 1 {
+		t.Fatalf("first load %d", v)
+	}
+
+	// 后台刷新 panic 不会使进程退出, 保留旧值, 之后可以再次刷新
+	clock.Add(8 * time.Second)
+
+	var deadline = time.Now().Add(time.Second)
+	for {
+		var v, err = cache.GetOrLoad("k", loader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v == 3 {
+			break
+		}
+		if v != 1 {
+			t.Fatalf("expect old value, got %d", v)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("not refreshed after panic")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGetOrLoadInvalidate(t *testing.T) {
+	var cache = lruv1.NewLoading(lruv1.NewTyped[string, int](0, nil))
+
+	var started = make(chan struct{})
+	var release = make(chan struct{})
+	var loader = func(key string) (int, error) {
+		started <- struct{}{}
+		<-release
+		return 1, nil
+	}
+
+	// 加载期间删除, 过时的加载结果不保存
+	var done = make(chan int)
+	go func() {
+		var v, _ = cache.GetOrLoad("k", loader)
+		done <- v
+	}()
+	<-started
+	cache.Del("k")
+	release <- struct{}{}
+	if v := <-done; v != 1 {
+		t.Fatalf("caller got %d", v)
+	}
+	if v, ok := cache.Get("k"); ok {
+		t.Fatalf("stale value cached: %d", v)
+	}
+
+	// 加载期间添加, 加载结果不覆盖新值
+	go func() {
+		var v, _ = cache.GetOrLoad("k", loader)
+		done <- v
+	}()
+	<-started
+	cache.Add("k", 100)
+	release <- struct{}{}
+	<-done
+	if v, ok := cache.Get("k"); !ok || v != 100 {
+		t.Fatalf("value overwritten: %d %v", v, ok)
+	}
+}
@@ -2,8 +2,8 @@ package lruv1
 
 import (
 	"bytes"
-	"container/list"
 	"fmt"
+	"time"
 )
 
 // LRU - Least Recently Used - 最近最少使用
@@ -20,6 +20,8 @@ import (
 // 2 每当缓存命中(即缓存数据被访问), 则将数据移到链表头部.
 // 3 当链表满的时候, 将链表尾部的数据丢弃.
 
+// 其他淘汰策略见 FIFO, LFU, ARC, TinyLFU, 均实现 Interface, 可通过 NewPolicy 按配置切换.
+
 // DeleteFunc 触发函数, 可选, 删除时触发
 type DeleteFunc func(string, Value)
 
@@ -29,129 +31,102 @@ type Value interface {
 }
 
 // Cache LRU缓存, 并发不安全
+// 按 len(key) + Value.Len() 计算占用内存的 TypedCache[string, Value]
 type Cache struct {
 	// 只能粗略限制计算内存大小
-	maxBytes  int64 // 允许使用的最大内存
-	currBytes int64 // 当前使用的内存
+	typed *TypedCache[string, Value]
+}
 
-	keysMap  map[string]*list.Element // key字典
-	linkList *list.List               // value链表
+// New 初始化
+func New(maxBytes int64, onDelete DeleteFunc) *Cache {
+	var typed = NewTyped[string, Value](maxBytes, nil)
+	typed.SetSizeFunc(func(key string, val Value) int64 {
+		return int64(len(key) + val.Len())
+	})
 
-	onDelete DeleteFunc
+	var c = &Cache{
+		typed: typed,
+	}
+	if onDelete != nil {
+		c.SetDeleteFunc(func(key string, val Value, _ EvictReason) {
+			onDelete(key, val)
+		})
+	}
+	return c
 }
 
-type entity struct {
-	key string
-	val Value
+// SetDeleteFunc 替换删除时的触发函数, 可获得删除原因
+func (c *Cache) SetDeleteFunc(onDelete TypedDeleteFunc[string, Value]) {
+	c.typed.SetDeleteFunc(onDelete)
 }
 
-func (e *entity) Len() int {
-	return len(e.key) + e.val.Len()
+// SetDefaultTTL 设置 Add 使用的默认过期时长, 0 表示不过期
+func (c *Cache) SetDefaultTTL(ttl time.Duration) {
+	c.typed.SetDefaultTTL(ttl)
 }
 
-// New 初始化
-func New(maxBytes int64, onDelete DeleteFunc) *Cache {
-	return &Cache{
-		maxBytes:  maxBytes,
-		currBytes: 0,
-		keysMap:   make(map[string]*list.Element),
-		linkList:  list.New(),
-		onDelete:  onDelete,
-	}
+// SetClock 设置判断过期使用的时钟
+func (c *Cache) SetClock(clock Clock) {
+	c.typed.SetClock(clock)
 }
 
-// Get 根据key获取元素
+// Get 根据key获取元素, 元素已过期时删除并返回不存在
 func (c *Cache) Get(key string) (Value, bool) {
-	if ele, ok := c.keysMap[key]; ok {
-		c.linkList.MoveToFront(ele)
-
-		var e = ele.Value.(*entity)
-		return e.val, true
-	}
-
-	return nil, false
+	return c.typed.Get(key)
 }
 
 // Del 根据key删除元素, 若key为空, 则根据LRU规则删除
 func (c *Cache) Del(key string) bool {
 	// 移除最近最少访问节点, 即队尾元素
 	if len(key) == 0 {
-		if ele := c.linkList.Back(); ele != nil {
-			c.linkList.Remove(ele)
-
-			var e = ele.Value.(*entity)
-			delete(c.keysMap, e.key)
-
-			c.currBytes -= int64(e.Len())
-
-			if c.onDelete != nil {
-				c.onDelete(e.key, e.val)
-			}
-
-			return true
-		}
-
-		return false
+		return c.typed.DelOldest()
 	}
 
 	// 移除指定元素
-	if ele, ok := c.keysMap[key]; ok {
-		c.linkList.Remove(ele)
-		var e = ele.Value.(*entity)
-		delete(c.keysMap, e.key)
-
-		c.currBytes -= int64(e.Len())
-
-		if c.onDelete != nil {
-			c.onDelete(e.key, e.val)
-		}
+	return c.typed.Del(key)
+}
 
-		return true
-	}
+// Add 添加元素, 使用默认过期时长
+func (c *Cache) Add(key string, value Value) {
+	c.typed.Add(key, value)
+}
 
-	return false
+// AddWithTTL 添加元素, {ttl} 后过期, ttl <= 0 时不过期
+func (c *Cache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	c.typed.AddWithTTL(key, value, ttl)
 }
 
-// Add 添加元素
-func (c *Cache) Add(key string, value Value) {
-	if ele, ok := c.keysMap[key]; ok {
-		c.linkList.MoveToFront(ele)
-
-		var e = ele.Value.(*entity)
-		c.currBytes += int64(value.Len() - e.val.Len())
-
-		e.val = value
-	} else {
-		var e = &entity{
-			key: key,
-			val: value,
-		}
-		var ele = c.linkList.PushFront(e)
-
-		c.keysMap[key] = ele
-		c.currBytes += int64(e.Len())
-	}
+// DelExpired 删除全部过期元素, 返回删除的个数
+func (c *Cache) DelExpired() int {
+	return c.typed.DelExpired()
+}
 
-	for c.maxBytes != 0 && c.maxBytes < c.currBytes {
-		c.Del("")
-	}
+// Stats 统计快照
+func (c *Cache) Stats() Stats {
+	return c.typed.Stats()
 }
 
+// String 返回容量及统计摘要, 列出全部元素见 Dump
 func (c *Cache) String() string {
+	return fmt.Sprintf("maxBytes: %d, %s", c.typed.maxSize, c.typed.Stats())
+}
+
+// Dump 列出全部元素, 元素较多时开销很大, 仅用于调试
+func (c *Cache) Dump() string {
 	var buf bytes.Buffer
 
-	buf.WriteString(fmt.Sprintf("maxBytes: %d, currBytes: %d\n", c.maxBytes, c.currBytes))
+	buf.WriteString(fmt.Sprintf("maxBytes: %d, currBytes: %d\n", c.typed.maxSize, c.typed.currSize))
 
 	buf.WriteString("keysMap:{ ")
-	for key := range c.keysMap {
+	for key := range c.typed.items {
 		buf.WriteString(key)
 		buf.WriteString(" ")
 	}
 	buf.WriteString("}\n")
 
 	buf.WriteString("linkList:\n")
-	for ele := c.linkList.Front(); ele != nil; ele = ele.Next() {
-		var e = ele.Value.(*entity)
+	for ele := c.typed.ll.Front(); ele != nil; ele = ele.Next() {
+		var e = ele.Value.(*typedEntity[string, Value])
 		buf.WriteString(fmt.Sprintf("key: %s, value: %v\n", e.key, e.val))
 	}
 	buf.WriteString("\n")
@@ -0,0 +1,123 @@
+package lruv1
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Interface 通用缓存接口, 各淘汰策略的缓存均实现该接口, 并发不安全
+type Interface[K comparable, V any] interface {
+	// Get 根据key获取元素
+	Get(key K) (V, bool)
+
+	// Add 添加元素, 超出容量时按淘汰策略删除元素
+	Add(key K, value V)
+
+	// Del 根据key删除元素
+	Del(key K) bool
+
+	// Len 元素个数
+	Len() int
+}
+
+var (
+	_ Interface[string, int] = (*TypedCache[string, int])(nil)
+	_ Interface[string, int] = (*FIFO[string, int])(nil)
+	_ Interface[string, int] = (*LFU[string, int])(nil)
+	_ Interface[string, int] = (*ARC[string, int])(nil)
+	_ Interface[string, int] = (*TinyLFU[string, int])(nil)
+)
+
+// Policy 淘汰策略
+type Policy int
+
+const (
+	// PolicyLRU 最近最少使用, 见 TypedCache
+	PolicyLRU Policy = iota
+
+	// PolicyFIFO 先进先出, 见 FIFO
+	PolicyFIFO
+
+	// PolicyLFU 最少使用, 见 LFU
+	PolicyLFU
+
+	// PolicyARC 自适应替换, 见 ARC
+	PolicyARC
+
+	// PolicyTinyLFU W-TinyLFU, 见 TinyLFU
+	PolicyTinyLFU
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyLRU:
+		return "lru"
+	case PolicyFIFO:
+		return "fifo"
+	case PolicyLFU:
+		return "lfu"
+	case PolicyARC:
+		return "arc"
+	case PolicyTinyLFU:
+		return "tinylfu"
+	}
+	return "unknown"
+}
+
+// NewPolicy 按淘汰策略初始化最多保存 {capacity} 个元素的缓存, 便于通过配置切换策略
+func NewPolicy[K comparable, V any](policy Policy, capacity int, onDelete TypedDeleteFunc[K, V]) (Interface[K, V], error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("invalid capacity %d", capacity)
+	}
+
+	switch policy {
+	case PolicyLRU:
+		return NewTyped[K, V](int64(capacity), onDelete), nil
+	case PolicyFIFO:
+		return NewFIFO[K, V](capacity, onDelete), nil
+	case PolicyLFU:
+		return NewLFU[K, V](capacity, onDelete), nil
+	case PolicyARC:
+		return NewARC[K, V](capacity, onDelete), nil
+	case PolicyTinyLFU:
+		return NewTinyLFU[K, V](capacity, onDelete), nil
+	}
+	return nil, fmt.Errorf("unknown policy %d", policy)
+}
+
+// hashKey 计算key的64位哈希, 用于 TinyLFU 的频率统计
+func hashKey[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return hashString(k)
+	case int:
+		return mix64(uint64(k))
+	case int32:
+		return mix64(uint64(k))
+	case int64:
+		return mix64(uint64(k))
+	case uint:
+		return mix64(uint64(k))
+	case uint32:
+		return mix64(uint64(k))
+	case uint64:
+		return mix64(k)
+	}
+	return hashString(fmt.Sprintf("%#v", key))
+}
+
+func hashString(s string) uint64 {
+	var h = fnv.New64a()
+	var _, _ = h.Write([]byte(s))
+	return mix64(h.Sum64())
+}
+
+// mix64 splitmix64 的混淆步骤, 使相邻整数的哈希分散
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,143 @@
+package lruv1_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+var policies = []lruv1.Policy{
+	lruv1.PolicyLRU,
+	lruv1.PolicyFIFO,
+	lruv1.PolicyLFU,
+	lruv1.PolicyARC,
+	lruv1.PolicyTinyLFU,
+}
+
+func TestPolicyCommon(t *testing.T) {
+	for _, p := range policies {
+		var live = make(map[int]bool)
+		var cache, err = lruv1.NewPolicy[int, string](p, 100, func(key int, val string, reason lruv1.EvictReason) {
+			if !live[key] {
+				t.Fatalf("%s: delete %d not live", p, key)
+			}
+			if val != strconv.Itoa(key) {
+				t.Fatalf("%s: delete %d with value %q", p, key, val)
+			}
+			delete(live, key)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 1000; i++ {
+			var key = i % 300
+			if i%3 == 0 {
+				key = i % 20
+			}
+
+			if v, ok := cache.Get(key); ok && v != strconv.Itoa(key) {
+				t.Fatalf("%s: get %d = %q", p, key, v)
+			}
+
+			if !live[key] {
+				live[key] = true
+				cache.Add(key, strconv.Itoa(key))
+			}
+
+			if cache.Len() > 100 {
+				t.Fatalf("%s: len %d over capacity", p, cache.Len())
+			}
+		}
+
+		if cache.Len() != len(live) {
+			t.Fatalf("%s: len %d, live %d", p, cache.Len(), len(live))
+		}
+
+		for key := range live {
+			if !cache.Del(key) {
+				t.Fatalf("%s: del %d", p, key)
+			}
+		}
+		if cache.Len() != 0 || len(live) != 0 {
+			t.Fatalf("%s: len %d after delete all", p, cache.Len())
+		}
+	}
+
+	if _, err := lruv1.NewPolicy[int, int](lruv1.Policy(100), 10, nil); err == nil {
+		t.Fatal("expect unknown policy error")
+	}
+}
+
+func TestFIFO(t *testing.T) {
+	var cache = lruv1.NewFIFO[string, int](2, nil)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	cache.Get("a")
+	cache.Add("c", 3)
+
+	// 访问不影响淘汰顺序
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("a should be evicted")
+	}
+}
+
+func TestLFU(t *testing.T) {
+	var evicted []string
+	var cache = lruv1.NewLFU[string, int](3, func(key string, val int, reason lruv1.EvictReason) {
+		evicted = append(evicted, key)
+	})
+
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+	cache.Add("c", 3)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b")
+
+	cache.Add("d", 4) // c 访问次数最少
+	cache.Add("e", 5) // d 与 e 之前, b 次数为2, d 次数为1
+
+	if len(evicted) != 2 || evicted[0] != "c" || evicted[1] != "d" {
+		t.Fatalf("evicted %v", evicted)
+	}
+}
+
+// scanResistance 热点数据访问多次后, 一次性扫描大量冷数据, 返回扫描后热点数据的命中数
+func scanResistance(cache lruv1.Interface[int, int]) int {
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 50; i++ {
+			if _, ok := cache.Get(i); !ok {
+				cache.Add(i, i)
+			}
+		}
+	}
+
+	for i := 1000; i < 1200; i++ {
+		if _, ok := cache.Get(i); !ok {
+			cache.Add(i, i)
+		}
+	}
+
+	var hits int
+	for i := 0; i < 50; i++ {
+		if _, ok := cache.Get(i); ok {
+			hits++
+		}
+	}
+	return hits
+}
+
+func TestScanResistance(t *testing.T) {
+	if hits := scanResistance(lruv1.NewTyped[int, int](100, nil)); hits != 0 {
+		t.Fatalf("lru keeps %d hot keys after scan", hits)
+	}
+
+	for _, p := range []lruv1.Policy{lruv1.PolicyARC, lruv1.PolicyTinyLFU, lruv1.PolicyLFU} {
+		var cache, _ = lruv1.NewPolicy[int, int](p, 100, nil)
+		if hits := scanResistance(cache); hits < 45 {
+			t.Fatalf("%s keeps %d hot keys after scan", p, hits)
+		}
+	}
+}
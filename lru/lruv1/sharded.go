@@ -0,0 +1,175 @@
+package lruv1
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultShards NewSharded 中 shards <= 0 时使用的分片数
+const DefaultShards = 16
+
+// ShardedCache 并发安全的LRU缓存
+// key 按哈希分配到多个独立加锁的 Cache 分片, 不同分片上的操作互不阻塞;
+// 每个分片单独按 maxBytes/分片数 淘汰, 淘汰顺序仅在分片内满足LRU
+type ShardedCache struct {
+	shards []*shard
+	next   uint32 // Del("") 轮询的起始分片
+}
+
+type shard struct {
+	mux   sync.Mutex
+	cache *Cache
+}
+
+// NewSharded 初始化, {maxBytes} 平均分配到 {shards} 个分片, maxBytes 为0时不限制
+// onDelete 在持有分片锁时调用, 不能在其中再操作同一缓存
+func NewSharded(shards int, maxBytes int64, onDelete DeleteFunc) *ShardedCache {
+	if shards <= 0 {
+		shards = DefaultShards
+	}
+
+	var c = &ShardedCache{
+		shards: make([]*shard, shards),
+	}
+
+	var per, rest = maxBytes / int64(shards), maxBytes % int64(shards)
+	for i := range c.shards {
+		var max = per
+		if int64(i) < rest {
+			max++
+		}
+		if maxBytes != 0 && max == 0 {
+			// 分片数多于 maxBytes 时, 保证每个分片都有限制
+			max = 1
+		}
+
+		c.shards[i] = &shard{
+			cache: New(max, onDelete),
+		}
+	}
+
+	return c
+}
+
+// shard FNV-1a 哈希选择分片
+func (c *ShardedCache) shard(key string) *shard {
+	var h uint32 = 2166136261
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return c.shards[h%uint32(len(c.shards))]
+}
+
+// Get 根据key获取元素, 元素已过期时删除并返回不存在
+func (c *ShardedCache) Get(key string) (Value, bool) {
+	var s = c.shard(key)
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.cache.Get(key)
+}
+
+// Add 添加元素, 使用默认过期时长
+func (c *ShardedCache) Add(key string, value Value) {
+	var s = c.shard(key)
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.cache.Add(key, value)
+}
+
+// AddWithTTL 添加元素, {ttl} 后过期, ttl <= 0 时不过期
+func (c *ShardedCache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	var s = c.shard(key)
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.cache.AddWithTTL(key, value, ttl)
+}
+
+// each 依次持有每个分片的锁调用 {fn}
+func (c *ShardedCache) each(fn func(cache *Cache)) {
+	for _, s := range c.shards {
+		s.mux.Lock()
+		fn(s.cache)
+		s.mux.Unlock()
+	}
+}
+
+// SetDeleteFunc 替换删除时的触发函数, 可获得删除原因; 在持有分片锁时调用
+func (c *ShardedCache) SetDeleteFunc(onDelete TypedDeleteFunc[string, Value]) {
+	c.each(func(cache *Cache) {
+		cache.SetDeleteFunc(onDelete)
+	})
+}
+
+// SetDefaultTTL 设置 Add 使用的默认过期时长, 0 表示不过期
+func (c *ShardedCache) SetDefaultTTL(ttl time.Duration) {
+	c.each(func(cache *Cache) {
+		cache.SetDefaultTTL(ttl)
+	})
+}
+
+// SetClock 设置判断过期使用的时钟
+func (c *ShardedCache) SetClock(clock Clock) {
+	c.each(func(cache *Cache) {
+		cache.SetClock(clock)
+	})
+}
+
+// DelExpired 依次清理每个分片的过期元素, 返回删除的个数
+func (c *ShardedCache) DelExpired() int {
+	var n int
+	c.each(func(cache *Cache) {
+		n += cache.DelExpired()
+	})
+	return n
+}
+
+// Stats 汇总各分片的统计快照
+func (c *ShardedCache) Stats() Stats {
+	var s Stats
+	c.each(func(cache *Cache) {
+		s.merge(cache.Stats())
+	})
+	return s
+}
+
+// StartJanitor 启动后台协程, 每隔 {interval} 调用一次 DelExpired, 调用返回值的 Stop 停止
+// {interval} 必须大于0
+func (c *ShardedCache) StartJanitor(interval time.Duration) (*Janitor, error) {
+	return NewJanitor(interval, func() {
+		c.DelExpired()
+	})
+}
+
+// Del 根据key删除元素, 若key为空, 则轮询分片, 删除第一个非空分片中最近最少访问的元素
+func (c *ShardedCache) Del(key string) bool {
+	if len(key) != 0 {
+		var s = c.shard(key)
+
+		s.mux.Lock()
+		defer s.mux.Unlock()
+
+		return s.cache.Del(key)
+	}
+
+	var start = atomic.AddUint32(&c.next, 1)
+	for i := 0; i < len(c.shards); i++ {
+		var s = c.shards[(start+uint32(i))%uint32(len(c.shards))]
+
+		s.mux.Lock()
+		var ok = s.cache.Del("")
+		s.mux.Unlock()
+
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package lruv1_test
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+func TestShardedCache(t *testing.T) {
+	var deleted int64
+	var cache = lruv1.NewSharded(4, 0, func(key string, val lruv1.Value) {
+		atomic.AddInt64(&deleted, 1)
+	})
+
+	cache.Add("a", String("A"))
+	cache.Add("b", String("B"))
+
+	if v, ok := cache.Get("a"); !ok || v.(String) != "A" {
+		t.Fatalf("get a: %v %v", v, ok)
+	}
+
+	cache.Add("a", String("AA"))
+	if v, _ := cache.Get("a"); v.(String) != "AA" {
+		t.Fatalf("update a: %v", v)
+	}
+
+	if !cache.Del("a") || cache.Del("a") {
+		t.Fatal("del a")
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("a still exists")
+	}
+
+	// 按LRU删除剩余的 b
+	if !cache.Del("") || cache.Del("") {
+		t.Fatal("del lru")
+	}
+	if deleted != 2 {
+		t.Fatalf("onDelete called %d times", deleted)
+	}
+}
+
+func TestShardedCacheConcurrent(t *testing.T) {
+	const shards, maxBytes = 8, 800
+
+	var mux sync.Mutex
+	var live = make(map[string]int)
+
+	var cache = lruv1.NewSharded(shards, maxBytes, func(key string, val lruv1.Value) {
+		mux.Lock()
+		live[key] -= len(key) + val.Len()
+		mux.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				var key = strconv.Itoa(g*2000 + i)
+
+				mux.Lock()
+				live[key] += len(key) + 10
+				mux.Unlock()
+
+				cache.Add(key, String("0123456789"))
+				cache.Get(strconv.Itoa(i))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// 淘汰后剩余的内存不超过限制
+	var total int
+	for key, n := range live {
+		if n != 0 {
+			if _, ok := cache.Get(key); !ok {
+				t.Fatalf("key %s evicted without onDelete", key)
+			}
+		}
+		total += n
+	}
+	if total > maxBytes || total == 0 {
+		t.Fatalf("total bytes %d, max %d", total, maxBytes)
+	}
+}
@@ -0,0 +1,201 @@
+package lruv1
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Codec 快照中 key/value 的编解码
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(b []byte) (T, error)
+}
+
+// JSONCodec 以 encoding/json 编解码
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec[T]) Decode(b []byte) (T, error) {
+	var v T
+	var err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+// StringCodec 字符串原样编解码
+type StringCodec struct{}
+
+func (StringCodec) Encode(s string) ([]byte, error) {
+	return []byte(s), nil
+}
+
+func (StringCodec) Decode(b []byte) (string, error) {
+	return string(b), nil
+}
+
+// snapshotMagic 快照文件头, 最后一字节为格式版本
+var snapshotMagic = []byte("LRUS\x01")
+
+// Snapshot 按最近访问顺序 (最近访问的在前) 将元素写入 {w}, 跳过已过期的元素, 返回写入的个数
+//
+// 格式: 文件头, 之后每个元素依次为
+// [key长度 uvarint] [key] [value长度 uvarint] [value] [过期时间 Unix纳秒 varint, 0 表示不过期]
+func (c *TypedCache[K, V]) Snapshot(w io.Writer, keys Codec[K], values Codec[V]) (int, error) {
+	var bw = bufio.NewWriter(w)
+	if _, err := bw.Write(snapshotMagic); err != nil {
+		return 0, err
+	}
+
+	var now = c.clock.Now()
+	var buf [binary.MaxVarintLen64]byte
+
+	var n int
+	for ele := c.ll.Front(); ele != nil; ele = ele.Next() {
+		var e = ele.Value.(*typedEntity[K, V])
+		if e.expired(now) {
+			continue
+		}
+
+		var kb, err = keys.Encode(e.key)
+		if err != nil {
+			return n, fmt.Errorf("encode key(%v) error: %w", e.key, err)
+		}
+		vb, err := values.Encode(e.val)
+		if err != nil {
+			return n, fmt.Errorf("encode key(%v) value error: %w", e.key, err)
+		}
+
+		var expire int64
+		if !e.expire.IsZero() {
+			expire = e.expire.UnixNano()
+		}
+
+		bw.Write(buf[:binary.PutUvarint(buf[:], uint64(len(kb)))])
+		bw.Write(kb)
+		bw.Write(buf[:binary.PutUvarint(buf[:], uint64(len(vb)))])
+		bw.Write(vb)
+		if _, err := bw.Write(buf[:binary.PutVarint(buf[:], expire)]); err != nil {
+			return n, err
+		}
+
+		n++
+	}
+
+	return n, bw.Flush()
+}
+
+// Restore 从 Snapshot 写入的 {r} 中加载元素, 返回加载的个数
+//
+// 快照中的元素按原顺序排在已有元素之后 (视为比已有元素更久未访问), 已存在的key及已过期的元素跳过;
+// 加入下一个元素会超出容量限制时停止加载, 即保留快照中最近访问的部分, 不会淘汰已有元素
+func (c *TypedCache[K, V]) Restore(r io.Reader, keys Codec[K], values Codec[V]) (int, error) {
+	var br = bufio.NewReader(r)
+
+	var magic = make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return 0, fmt.Errorf("read snapshot header error: %w", err)
+	}
+	if string(magic) != string(snapshotMagic) {
+		return 0, fmt.Errorf("invalid snapshot header %q", magic)
+	}
+
+	var now = c.clock.Now()
+
+	var n int
+	for {
+		var kb, err = readBytes(br)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, fmt.Errorf("read key error: %w", err)
+		}
+
+		vb, err := readBytes(br)
+		if err != nil {
+			return n, fmt.Errorf("read value error: %w", unexpected(err))
+		}
+
+		expire, err := binary.ReadVarint(br)
+		if err != nil {
+			return n, fmt.Errorf("read expire error: %w", unexpected(err))
+		}
+
+		var e = &typedEntity[K, V]{}
+		if expire != 0 {
+			e.expire = time.Unix(0, expire)
+			if e.expired(now) {
+				continue
+			}
+		}
+
+		if e.key, err = keys.Decode(kb); err != nil {
+			return n, fmt.Errorf("decode key error: %w", err)
+		}
+		if _, ok := c.items[e.key]; ok {
+			continue
+		}
+
+		if e.val, err = values.Decode(vb); err != nil {
+			return n, fmt.Errorf("decode key(%v) value error: %w", e.key, err)
+		}
+
+		e.size = c.sizeOf(e.key, e.val)
+		if c.maxSize != 0 && c.currSize+e.size > c.maxSize {
+			return n, nil
+		}
+
+		c.items[e.key] = c.ll.PushBack(e)
+		c.currSize += e.size
+		c.stats.Adds++
+
+		n++
+	}
+}
+
+// maxSnapshotField 快照中单个 key/value 的最大长度
+const maxSnapshotField = 1<<31 - 1
+
+// readBytes 读取 uvarint 长度及对应的字节
+// 按实际读到的数据分配内存, 数据损坏时返回错误而不是按错误的长度分配
+func readBytes(br *bufio.Reader) ([]byte, error) {
+	var l, err = binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if l > maxSnapshotField {
+		return nil, fmt.Errorf("invalid length %d, max %d", l, maxSnapshotField)
+	}
+
+	var b bytes.Buffer
+	if _, err := io.CopyN(&b, br, int64(l)); err != nil {
+		return nil, unexpected(err)
+	}
+	return b.Bytes(), nil
+}
+
+// unexpected 元素中途遇到 EOF 视为数据不完整
+func unexpected(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Snapshot 按最近访问顺序将元素写入 {w}, 见 TypedCache.Snapshot
+func (c *Cache) Snapshot(w io.Writer, values Codec[Value]) (int, error) {
+	return c.typed.Snapshot(w, StringCodec{}, values)
+}
+
+// Restore 从 {r} 中加载元素, 不超过 maxBytes, 见 TypedCache.Restore
+func (c *Cache) Restore(r io.Reader, values Codec[Value]) (int, error) {
+	return c.typed.Restore(r, StringCodec{}, values)
+}
@@ -0,0 +1,166 @@
+package lruv1_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+// stringValueCodec Cache 中 String 类型值的编解码
+type stringValueCodec struct{}
+
+func (stringValueCodec) Encode(v lruv1.Value) ([]byte, error) {
+	return []byte(v.(String)), nil
+}
+
+func (stringValueCodec) Decode(b []byte) (lruv1.Value, error) {
+	return String(b), nil
+}
+
+// drain 从最久未访问开始逐个删除, 返回删除的key顺序
+func drain(cache *lruv1.TypedCache[string, int]) []string {
+	var keys []string
+	cache.SetDeleteFunc(func(key string, val int, reason lruv1.EvictReason) {
+		keys = append(keys, key)
+	})
+	for cache.Len() > 0 {
+		cache.DelOldest()
+	}
+	return keys
+}
+
+func TestSnapshot(t *testing.T) {
+	var clock = newFakeClock()
+
+	var cache = lruv1.NewTyped[string, int](0, nil)
+	cache.SetClock(clock)
+	cache.AddWithTTL("a", 1, time.Minute)
+	cache.Add("b", 2)
+	cache.AddWithTTL("c", 3, time.Second)
+	cache.Add("d", 4)
+	cache.Get("a")
+
+	var buf bytes.Buffer
+	if n, err := cache.Snapshot(&buf, lruv1.StringCodec{}, lruv1.JSONCodec[int]{}); err != nil || n != 4 {
+		t.Fatalf("snapshot %d %v", n, err)
+	}
+	var data = buf.Bytes()
+
+	// c 在加载前过期
+	clock.Add(2 * time.Second)
+
+	var loaded = lruv1.NewTyped[string, int](0, nil)
+	loaded.SetClock(clock)
+	if n, err := loaded.Restore(bytes.NewReader(data), lruv1.StringCodec{}, lruv1.JSONCodec[int]{}); err != nil || n != 3 {
+		t.Fatalf("restore %d %v", n, err)
+	}
+	if s := loaded.Stats(); s.Adds != 3 || s.Entries != 3 {
+		t.Fatalf("stats %v", s)
+	}
+
+	// 保留剩余有效期
+	clock.Add(time.Minute)
+	if _, ok := loaded.Get("a"); ok {
+		t.Fatal("a not expired")
+	}
+	if v, ok := loaded.Get("b"); !ok || v != 2 {
+		t.Fatalf("b: %d %v", v, ok)
+	}
+
+	// 保留访问顺序: b d a c -> 过期后 d b
+	loaded = lruv1.NewTyped[string, int](0, nil)
+	loaded.SetClock(clock)
+	loaded.Restore(bytes.NewReader(data), lruv1.StringCodec{}, lruv1.JSONCodec[int]{})
+	if keys := drain(loaded); len(keys) != 2 || keys[0] != "b" || keys[1] != "d" {
+		t.Fatalf("order %v", keys)
+	}
+}
+
+func TestRestoreBudget(t *testing.T) {
+	var cache = lruv1.NewTyped[string, int](0, nil)
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		cache.Add(key, i)
+	}
+
+	var buf bytes.Buffer
+	cache.Snapshot(&buf, lruv1.StringCodec{}, lruv1.JSONCodec[int]{})
+
+	// 容量不足时保留最近访问的元素, 已有元素不被淘汰且比快照中的元素新
+	var evicted int
+	var loaded = lruv1.NewTyped[string, int](3, func(key string, val int, reason lruv1.EvictReason) {
+		evicted++
+	})
+	loaded.Add("d", 100)
+	if n, err := loaded.Restore(&buf, lruv1.StringCodec{}, lruv1.JSONCodec[int]{}); err != nil || n != 2 {
+		t.Fatalf("restore %d %v", n, err)
+	}
+	if evicted != 0 {
+		t.Fatalf("evicted %d", evicted)
+	}
+	if v, _ := loaded.Get("d"); v != 100 {
+		t.Fatalf("d overwritten: %d", v)
+	}
+	if keys := drain(loaded); len(keys) != 3 || keys[0] != "c" || keys[1] != "e" || keys[2] != "d" {
+		t.Fatalf("order %v", keys)
+	}
+}
+
+func TestCacheSnapshot(t *testing.T) {
+	var cache = lruv1.New(0, nil)
+	cache.Add("a", String("A"))
+	cache.Add("b", String("BB"))
+	cache.Add("c", String("CCC"))
+
+	var buf bytes.Buffer
+	if _, err := cache.Snapshot(&buf, stringValueCodec{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 字节预算 6 只能容纳 c(4) 之后的 a(2), b(3) 放不下
+	var loaded = lruv1.New(6, nil)
+	if n, err := loaded.Restore(&buf, stringValueCodec{}); err != nil || n != 1 {
+		t.Fatalf("restore %d %v", n, err)
+	}
+	if v, ok := loaded.Get("c"); !ok || v.(String) != "CCC" {
+		t.Fatalf("c: %v %v", v, ok)
+	}
+	if s := loaded.Stats(); s.Bytes != 4 {
+		t.Fatalf("bytes %d", s.Bytes)
+	}
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	var cache = lruv1.NewTyped[string, int](0, nil)
+	cache.Add("a", 1)
+	cache.Add("b", 2)
+
+	var buf bytes.Buffer
+	cache.Snapshot(&buf, lruv1.StringCodec{}, lruv1.JSONCodec[int]{})
+	var data = buf.Bytes()
+
+	var loaded = lruv1.NewTyped[string, int](0, nil)
+	if _, err := loaded.Restore(bytes.NewReader([]byte("bad header")), lruv1.StringCodec{}, lruv1.JSONCodec[int]{}); err == nil {
+		t.Fatal("expect header error")
+	}
+
+	// 数据不完整时保留已加载的元素
+	var n, err = loaded.Restore(bytes.NewReader(data[:len(data)-2]), lruv1.StringCodec{}, lruv1.JSONCodec[int]{})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || n != 1 {
+		t.Fatalf("truncated %d %v", n, err)
+	}
+
+	// 长度损坏时返回错误, 不按该长度分配内存
+	for _, l := range [][]byte{
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+		{0xff, 0xff, 0xff, 0x7f},
+	} {
+		var corrupted = append([]byte("LRUS\x01"), l...)
+		if _, err := loaded.Restore(bytes.NewReader(corrupted), lruv1.StringCodec{}, lruv1.JSONCodec[int]{}); err == nil {
+			t.Fatalf("corrupted length %x: expect error", l)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package lruv1
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Stats 缓存统计快照, 除 Entries/Bytes 外均为创建以来的累计值
+type Stats struct {
+	Hits    int64 // 命中次数
+	Misses  int64 // 未命中次数, 含因过期未命中
+	Adds    int64 // 新增元素次数
+	Updates int64 // 更新已有元素次数
+
+	EvictedExplicit int64 // 调用 Del/DelOldest 删除的元素个数
+	EvictedCapacity int64 // 超出容量淘汰的元素个数
+	EvictedExpired  int64 // 过期删除的元素个数
+
+	Entries int64 // 当前元素个数
+	Bytes   int64 // 当前总大小, Cache 中为内存字节数
+}
+
+// HitRatio 命中率, 没有访问时为0
+func (s Stats) HitRatio() float64 {
+	var total = s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// Evicted 按删除原因返回删除的元素个数
+func (s Stats) Evicted(reason EvictReason) int64 {
+	switch reason {
+	case EvictExplicit:
+		return s.EvictedExplicit
+	case EvictCapacity:
+		return s.EvictedCapacity
+	case EvictExpired:
+		return s.EvictedExpired
+	}
+	return 0
+}
+
+// evicted 删除计数加1
+func (s *Stats) evicted(reason EvictReason) {
+	switch reason {
+	case EvictExplicit:
+		s.EvictedExplicit++
+	case EvictCapacity:
+		s.EvictedCapacity++
+	case EvictExpired:
+		s.EvictedExpired++
+	}
+}
+
+// merge 累加 {o}
+func (s *Stats) merge(o Stats) {
+	s.Hits += o.Hits
+	s.Misses += o.Misses
+	s.Adds += o.Adds
+	s.Updates += o.Updates
+	s.EvictedExplicit += o.EvictedExplicit
+	s.EvictedCapacity += o.EvictedCapacity
+	s.EvictedExpired += o.EvictedExpired
+	s.Entries += o.Entries
+	s.Bytes += o.Bytes
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("entries: %d, bytes: %d, hits: %d, misses: %d, hit ratio: %.4f, adds: %d, updates: %d, evicted(explicit: %d, capacity: %d, expired: %d)",
+		s.Entries, s.Bytes, s.Hits, s.Misses, s.HitRatio(), s.Adds, s.Updates,
+		s.EvictedExplicit, s.EvictedCapacity, s.EvictedExpired)
+}
+
+// StatsSource 可提供统计快照的缓存, 需并发安全 (ShardedCache, LoadingCache)
+// 并发不安全的缓存可包装为加锁后返回 Stats() 的 StatsFunc
+type StatsSource interface {
+	Stats() Stats
+}
+
+// StatsFunc 以函数实现 StatsSource
+type StatsFunc func() Stats
+
+func (f StatsFunc) Stats() Stats {
+	return f()
+}
+
+// Exporter 以 Prometheus 文本格式导出多个缓存的统计, 实现 http.Handler, 并发安全
+//
+//	var exporter = lruv1.NewExporter("myapp")
+//	exporter.Register("user", userCache)
+//	http.Handle("/metrics/cache", exporter)
+//
+// 指标 (以 cache 标签区分缓存):
+//
+//	{namespace}_cache_hits_total, {namespace}_cache_misses_total,
+//	{namespace}_cache_adds_total, {namespace}_cache_updates_total,
+//	{namespace}_cache_evictions_total{reason="explicit|capacity|expired"},
+//	{namespace}_cache_entries, {namespace}_cache_bytes
+type Exporter struct {
+	mux       sync.RWMutex
+	namespace string
+	sources   map[string]StatsSource
+}
+
+// NewExporter 初始化, {namespace} 为指标名前缀, 为空时无前缀
+func NewExporter(namespace string) *Exporter {
+	return &Exporter{
+		namespace: namespace,
+		sources:   make(map[string]StatsSource),
+	}
+}
+
+// Register 注册缓存, {name} 作为 cache 标签的值
+func (e *Exporter) Register(name string, source StatsSource) error {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if _, ok := e.sources[name]; ok {
+		return fmt.Errorf("cache (%s) has registed", name)
+	}
+	e.sources[name] = source
+	return nil
+}
+
+// Unregister 注销缓存
+func (e *Exporter) Unregister(name string) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	delete(e.sources, name)
+}
+
+// metric 一个指标在各缓存上的取值
+type metric struct {
+	name  string
+	kind  string // counter, gauge
+	help  string
+	value func(s Stats) int64
+}
+
+var metrics = []metric{
+	{"hits_total", "counter", "Number of cache hits.", func(s Stats) int64 { return s.Hits }},
+	{"misses_total", "counter", "Number of cache misses.", func(s Stats) int64 { return s.Misses }},
+	{"adds_total", "counter", "Number of new entries added.", func(s Stats) int64 { return s.Adds }},
+	{"updates_total", "counter", "Number of existing entries updated.", func(s Stats) int64 { return s.Updates }},
+	{"entries", "gauge", "Current number of entries.", func(s Stats) int64 { return s.Entries }},
+	{"bytes", "gauge", "Current total size of entries.", func(s Stats) int64 { return s.Bytes }},
+}
+
+// WriteTo 写入 Prometheus 文本格式的统计
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mux.RLock()
+	var names = make([]string, 0, len(e.sources))
+	var stats = make(map[string]Stats, len(e.sources))
+	for name, source := range e.sources {
+		names = append(names, name)
+		stats[name] = source.Stats()
+	}
+	e.mux.RUnlock()
+
+	sort.Strings(names)
+
+	var cw = &countWriter{w: w}
+	var bw = bufio.NewWriter(cw)
+
+	var prefix = "cache_"
+	if e.namespace != "" {
+		prefix = e.namespace + "_cache_"
+	}
+
+	for _, m := range metrics {
+		fmt.Fprintf(bw, "# HELP %s%s %s\n", prefix, m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s%s %s\n", prefix, m.name, m.kind)
+		for _, name := range names {
+			fmt.Fprintf(bw, "%s%s{cache=\"%s\"} %d\n", prefix, m.name, escapeLabel(name), m.value(stats[name]))
+		}
+	}
+
+	fmt.Fprintf(bw, "# HELP %sevictions_total Number of entries removed, by reason.\n", prefix)
+	fmt.Fprintf(bw, "# TYPE %sevictions_total counter\n", prefix)
+	for _, name := range names {
+		for _, reason := range []EvictReason{EvictExplicit, EvictCapacity, EvictExpired} {
+			fmt.Fprintf(bw, "%sevictions_total{cache=\"%s\",reason=\"%s\"} %d\n",
+				prefix, escapeLabel(name), reason, stats[name].Evicted(reason))
+		}
+	}
+
+	var err = bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP 实现 http.Handler
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	var _, _ = e.WriteTo(w)
+}
+
+// escapeLabel 转义标签值中的 \ " 及换行
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// countWriter 统计写入的字节数
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	var n, err = c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,115 @@
+package lruv1_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+func TestStats(t *testing.T) {
+	var clock = newFakeClock()
+
+	var cache = lruv1.New(10, nil)
+	cache.SetClock(clock)
+
+	cache.Add("a", String("1"))                     // 2 bytes
+	cache.Add("b", String("22"))                    // 3 bytes
+	cache.Add("a", String("111"))                   // 更新为 4 bytes
+	cache.AddWithTTL("c", String("3"), time.Second) // 2 bytes
+	cache.Add("d", String("4"))                     // 2 bytes, 共 11 bytes, 淘汰 b
+
+	cache.Get("a")
+	cache.Get("b")
+	clock.Add(time.Second)
+	cache.Get("c") // 过期
+	cache.Del("d")
+
+	var s = cache.Stats()
+	var want = lruv1.Stats{
+		Hits:            1,
+		Misses:          2,
+		Adds:            4,
+		Updates:         1,
+		EvictedExplicit: 1,
+		EvictedCapacity: 1,
+		EvictedExpired:  1,
+		Entries:         1,
+		Bytes:           4,
+	}
+	if s != want {
+		t.Fatalf("stats %+v, want %+v", s, want)
+	}
+	if s.HitRatio() < 0.33 || s.HitRatio() > 0.34 {
+		t.Fatalf("hit ratio %f", s.HitRatio())
+	}
+	if !strings.Contains(cache.String(), "entries: 1, bytes: 4") {
+		t.Fatalf("string %s", cache)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	var sharded = lruv1.NewSharded(4, 0, nil)
+	for _, key := range []string{"a", "b", "c"} {
+		sharded.Add(key, String("v"))
+		sharded.Get(key)
+	}
+	sharded.Get("x")
+	sharded.Del("a")
+
+	var typed = lruv1.NewTyped[int, int](1, nil)
+	typed.Add(1, 1)
+	typed.Add(2, 2)
+
+	var exporter = lruv1.NewExporter("app")
+	if err := exporter.Register("sharded", sharded); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Register(`ty"ped`, lruv1.StatsFunc(typed.Stats)); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Register("sharded", sharded); err == nil {
+		t.Fatal("expect duplicate error")
+	}
+
+	var server = httptest.NewServer(exporter)
+	defer server.Close()
+
+	var resp, err = server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("content type %s", ct)
+	}
+
+	var body, _ = io.ReadAll(resp.Body)
+	for _, line := range []string{
+		"# TYPE app_cache_hits_total counter",
+		`app_cache_hits_total{cache="sharded"} 3`,
+		`app_cache_misses_total{cache="sharded"} 1`,
+		`app_cache_entries{cache="sharded"} 2`,
+		`app_cache_bytes{cache="sharded"} 4`,
+		`app_cache_evictions_total{cache="sharded",reason="explicit"} 1`,
+		`app_cache_evictions_total{cache="ty\"ped",reason="capacity"} 1`,
+		`app_cache_adds_total{cache="ty\"ped"} 2`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Fatalf("missing %q in:\n%s", line, body)
+		}
+	}
+
+	exporter.Unregister("sharded")
+	var buf strings.Builder
+	if _, err := exporter.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `cache="sharded"`) {
+		t.Fatal("unregistered cache exported")
+	}
+}
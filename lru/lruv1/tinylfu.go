@@ -0,0 +1,242 @@
+package lruv1
+
+import "container/list"
+
+// TinyLFU W-TinyLFU 缓存, 最多保存 capacity 个元素, 并发不安全
+//
+// 新元素先进入占容量 1% 的窗口LRU; 被窗口淘汰的候选者与主缓存 (SLRU: 20% 试用区 + 80% 保护区)
+// 试用区的淘汰者比较 count-min sketch 估算的访问频率, 频率更高者留下, 低频的一次性访问不会冲刷主缓存
+//
+// 见 Einziger, Friedman & Manes, "TinyLFU: A Highly Efficient Cache Admission Policy", 2017
+type TinyLFU[K comparable, V any] struct {
+	capacity     int
+	windowCap    int
+	mainCap      int
+	protectedCap int
+
+	window    *list.List // 队首为最近访问
+	probation *list.List
+	protected *list.List
+
+	items  map[K]*list.Element
+	sketch *cmSketch
+
+	onDelete TypedDeleteFunc[K, V]
+}
+
+type tinyLFUEntity[K comparable, V any] struct {
+	key  K
+	val  V
+	hash uint64
+	ll   *list.List // 所在区域
+}
+
+// NewTinyLFU 初始化, {capacity} 小于1时按1处理
+func NewTinyLFU[K comparable, V any](capacity int, onDelete TypedDeleteFunc[K, V]) *TinyLFU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	var windowCap = maxInt(capacity/100, 1)
+	var mainCap = capacity - windowCap
+
+	return &TinyLFU[K, V]{
+		capacity:     capacity,
+		windowCap:    windowCap,
+		mainCap:      mainCap,
+		protectedCap: mainCap * 8 / 10,
+
+		window:    list.New(),
+		probation: list.New(),
+		protected: list.New(),
+
+		items:  make(map[K]*list.Element),
+		sketch: newCMSketch(capacity),
+
+		onDelete: onDelete,
+	}
+}
+
+// Get 根据key获取元素, 无论是否命中都记录一次访问
+func (c *TinyLFU[K, V]) Get(key K) (V, bool) {
+	var ele, ok = c.items[key]
+	if !ok {
+		c.sketch.increment(hashKey(key))
+
+		var zero V
+		return zero, false
+	}
+
+	var e = ele.Value.(*tinyLFUEntity[K, V])
+	c.sketch.increment(e.hash)
+	c.touch(ele)
+	return e.val, true
+}
+
+// Add 添加元素, 已存在时更新值并记录一次访问
+func (c *TinyLFU[K, V]) Add(key K, value V) {
+	if ele, ok := c.items[key]; ok {
+		var e = ele.Value.(*tinyLFUEntity[K, V])
+		e.val = value
+		c.sketch.increment(e.hash)
+		c.touch(ele)
+		return
+	}
+
+	var e = &tinyLFUEntity[K, V]{key: key, val: value, hash: hashKey(key), ll: c.window}
+	c.sketch.increment(e.hash)
+	c.items[key] = c.window.PushFront(e)
+
+	if c.window.Len() > c.windowCap {
+		c.admit(c.window.Back())
+	}
+}
+
+// Del 根据key删除元素
+func (c *TinyLFU[K, V]) Del(key K) bool {
+	if ele, ok := c.items[key]; ok {
+		c.remove(ele, EvictExplicit)
+		return true
+	}
+	return false
+}
+
+// Len 元素个数
+func (c *TinyLFU[K, V]) Len() int {
+	return len(c.items)
+}
+
+// touch 命中后调整位置: 窗口/保护区内移到队首, 试用区晋升到保护区
+func (c *TinyLFU[K, V]) touch(ele *list.Element) {
+	var e = ele.Value.(*tinyLFUEntity[K, V])
+
+	if e.ll != c.probation {
+		e.ll.MoveToFront(ele)
+		return
+	}
+
+	c.probation.Remove(ele)
+	e.ll = c.protected
+	c.items[e.key] = c.protected.PushFront(e)
+
+	// 保护区已满, 最久未访问的降级到试用区
+	if c.protected.Len() > c.protectedCap {
+		var back = c.protected.Back()
+		var demoted = back.Value.(*tinyLFUEntity[K, V])
+
+		c.protected.Remove(back)
+		demoted.ll = c.probation
+		c.items[demoted.key] = c.probation.PushFront(demoted)
+	}
+}
+
+// admit 窗口淘汰的候选者进入主缓存, 主缓存已满时与试用区的淘汰者比较访问频率
+func (c *TinyLFU[K, V]) admit(ele *list.Element) {
+	var candidate = ele.Value.(*tinyLFUEntity[K, V])
+
+	if c.probation.Len()+c.protected.Len() >= c.mainCap {
+		var victim = c.probation.Back()
+		if victim == nil {
+			victim = c.protected.Back()
+		}
+
+		if victim == nil || c.sketch.estimate(candidate.hash) <= c.sketch.estimate(victim.Value.(*tinyLFUEntity[K, V]).hash) {
+			c.remove(ele, EvictCapacity)
+			return
+		}
+		c.remove(victim, EvictCapacity)
+	}
+
+	c.window.Remove(ele)
+	candidate.ll = c.probation
+	c.items[candidate.key] = c.probation.PushFront(candidate)
+}
+
+func (c *TinyLFU[K, V]) remove(ele *list.Element, reason EvictReason) {
+	var e = ele.Value.(*tinyLFUEntity[K, V])
+
+	e.ll.Remove(ele)
+	delete(c.items, e.key)
+
+	if c.onDelete != nil {
+		c.onDelete(e.key, e.val, reason)
+	}
+}
+
+// cmSketchDepth count-min sketch 的行数
+const cmSketchDepth = 4
+
+// cmSketch 4位计数器的 count-min sketch, 估算访问频率
+// 累计记录次数达到 10 倍容量时全部计数减半, 使旧的访问频率逐渐衰减
+type cmSketch struct {
+	rows  [cmSketchDepth][]uint8 // 每个字节存两个4位计数器
+	mask  uint64
+	added int
+	reset int
+}
+
+var cmSketchSeeds = [cmSketchDepth]uint64{
+	0xc3a5c85c97cb3127, 0xb492b66fbe98f273, 0x9ae16a3b2f90404f, 0xcbf29ce484222325,
+}
+
+func newCMSketch(capacity int) *cmSketch {
+	// 计数器数量取不小于容量的2的幂
+	var width = 16
+	for width < capacity {
+		width <<= 1
+	}
+
+	var s = &cmSketch{
+		mask:  uint64(width - 1),
+		reset: 10 * capacity,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width/2)
+	}
+	return s
+}
+
+// index 第 {i} 行中的计数器位置
+func (s *cmSketch) index(hash uint64, i int) uint64 {
+	return mix64(hash^cmSketchSeeds[i]) & s.mask
+}
+
+func (s *cmSketch) get(i int, idx uint64) uint8 {
+	return (s.rows[i][idx/2] >> ((idx & 1) * 4)) & 0x0f
+}
+
+// increment 记录一次访问, 计数器最大为15
+func (s *cmSketch) increment(hash uint64) {
+	for i := range s.rows {
+		var idx = s.index(hash, i)
+		if s.get(i, idx) < 15 {
+			s.rows[i][idx/2] += 1 << ((idx & 1) * 4)
+		}
+	}
+
+	s.added++
+	if s.added >= s.reset {
+		s.halve()
+	}
+}
+
+// estimate 估算访问频率, 取各行计数器的最小值
+func (s *cmSketch) estimate(hash uint64) uint8 {
+	var min uint8 = 15
+	for i := range s.rows {
+		if v := s.get(i, s.index(hash, i)); v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// halve 全部计数器减半
+func (s *cmSketch) halve() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] = (s.rows[i][j] >> 1) & 0x77
+		}
+	}
+	s.added /= 2
+}
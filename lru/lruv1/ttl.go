@@ -0,0 +1,103 @@
+package lruv1
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EvictReason 元素被删除的原因
+type EvictReason int
+
+const (
+	// EvictExplicit 调用 Del/DelOldest 删除
+	EvictExplicit EvictReason = iota
+
+	// EvictCapacity 超出容量限制被淘汰
+	EvictCapacity
+
+	// EvictExpired 过期被删除 (Get 时惰性删除或 DelExpired 清理)
+	EvictExpired
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExplicit:
+		return "explicit"
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	}
+	return "unknown"
+}
+
+// Clock 时钟, 可在测试中替换
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock 系统时钟
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Janitor 定期清理过期元素的后台协程
+type Janitor struct {
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewJanitor 每隔 {interval} 调用一次 {sweep}, {interval} 必须大于0
+// TypedCache/Cache 并发不安全, {sweep} 中需与其他操作使用同一把锁, 例:
+//
+//	var j, err = lruv1.NewJanitor(time.Minute, func() {
+//		mux.Lock()
+//		defer mux.Unlock()
+//		cache.DelExpired()
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer j.Stop()
+func NewJanitor(interval time.Duration, sweep func()) (*Janitor, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid janitor interval %v, require > 0", interval)
+	}
+
+	var j = &Janitor{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(j.done)
+
+		var ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				sweep()
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+
+	return j, nil
+}
+
+// Stop 停止清理, 等待正在进行的清理结束, 可重复调用
+func (j *Janitor) Stop() {
+	j.once.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+}
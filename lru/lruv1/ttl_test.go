@@ -0,0 +1,132 @@
+package lruv1_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+// fakeClock 手动控制的时钟
+type fakeClock struct {
+	mux sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1600000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestTTL(t *testing.T) {
+	var clock = newFakeClock()
+
+	var expired []string
+	var cache = lruv1.NewTyped[string, int](0, func(key string, val int, reason lruv1.EvictReason) {
+		if reason == lruv1.EvictExpired {
+			expired = append(expired, key)
+		}
+	})
+	cache.SetClock(clock)
+	cache.SetDefaultTTL(time.Minute)
+
+	cache.Add("default", 1)
+	cache.AddWithTTL("short", 2, time.Second)
+	cache.AddWithTTL("forever", 3, 0)
+
+	clock.Add(time.Second)
+
+	// Get 时惰性删除
+	if _, ok := cache.Get("short"); ok {
+		t.Fatal("short not expired")
+	}
+	if v, ok := cache.Get("default"); !ok || v != 1 {
+		t.Fatalf("default: %d %v", v, ok)
+	}
+
+	// 更新会刷新过期时间
+	clock.Add(30 * time.Second)
+	cache.Add("default", 10)
+	clock.Add(45 * time.Second)
+	if v, ok := cache.Get("default"); !ok || v != 10 {
+		t.Fatalf("default after update: %d %v", v, ok)
+	}
+
+	clock.Add(time.Hour)
+	if n := cache.DelExpired(); n != 1 {
+		t.Fatalf("del expired %d", n)
+	}
+	if _, ok := cache.Get("forever"); !ok || cache.Len() != 1 {
+		t.Fatal("forever expired")
+	}
+
+	if len(expired) != 2 || expired[0] != "short" || expired[1] != "default" {
+		t.Fatalf("expired %v", expired)
+	}
+}
+
+func TestShardedJanitor(t *testing.T) {
+	var clock = newFakeClock()
+
+	var mux sync.Mutex
+	var reasons = make(map[string]lruv1.EvictReason)
+
+	var cache = lruv1.NewSharded(4, 0, nil)
+	cache.SetClock(clock)
+	cache.SetDeleteFunc(func(key string, val lruv1.Value, reason lruv1.EvictReason) {
+		mux.Lock()
+		reasons[key] = reason
+		mux.Unlock()
+	})
+
+	cache.AddWithTTL("a", String("A"), time.Second)
+	cache.AddWithTTL("b", String("B"), time.Hour)
+	cache.Add("c", String("C"))
+	cache.Del("c")
+
+	if _, err := cache.StartJanitor(0); err == nil {
+		t.Fatal("expect invalid interval error")
+	}
+
+	janitor, err := cache.StartJanitor(time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Add(time.Minute)
+
+	var deadline = time.Now().Add(time.Second)
+	for {
+		mux.Lock()
+		var _, ok = reasons["a"]
+		mux.Unlock()
+		if ok || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	janitor.Stop()
+	janitor.Stop()
+
+	mux.Lock()
+	defer mux.Unlock()
+
+	if reasons["a"] != lruv1.EvictExpired || reasons["c"] != lruv1.EvictExplicit {
+		t.Fatalf("reasons %v", reasons)
+	}
+	if _, ok := reasons["b"]; ok {
+		t.Fatal("b should not be deleted")
+	}
+}
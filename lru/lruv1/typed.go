@@ -0,0 +1,234 @@
+package lruv1
+
+import (
+	"container/list"
+	"time"
+)
+
+// SizeFunc 计算元素占用的大小
+type SizeFunc[K comparable, V any] func(key K, value V) int64
+
+// TypedDeleteFunc 触发函数, 可选, 删除时触发, {reason} 为删除原因
+type TypedDeleteFunc[K comparable, V any] func(key K, value V, reason EvictReason)
+
+// TypedCache 泛型LRU缓存, 并发不安全
+// 未设置 SizeFunc 时每个元素大小为1, 即按元素个数限制; 字符串版本的 Cache 即 TypedCache[string, Value]
+// 元素可设置过期时间, 过期元素在 Get 时惰性删除, 或由 DelExpired 统一清理
+type TypedCache[K comparable, V any] struct {
+	maxSize  int64 // 允许的最大总大小, 0 表示不限制
+	currSize int64 // 当前总大小
+
+	size SizeFunc[K, V]
+
+	ttl   time.Duration // Add 使用的默认过期时长, 0 表示不过期
+	clock Clock
+
+	items map[K]*list.Element // key字典
+	ll    *list.List          // value链表, 队首为最近访问
+
+	onDelete TypedDeleteFunc[K, V]
+
+	stats Stats // 累计计数, Entries/Bytes 在 Stats() 中填充
+}
+
+type typedEntity[K comparable, V any] struct {
+	key    K
+	val    V
+	size   int64
+	expire time.Time // 过期时间, 零值表示不过期
+}
+
+// expired 在 {now} 时是否已过期
+func (e *typedEntity[K, V]) expired(now time.Time) bool {
+	return !e.expire.IsZero() && !now.Before(e.expire)
+}
+
+// NewTyped 初始化, {maxSize} 为0时不限制
+func NewTyped[K comparable, V any](maxSize int64, onDelete TypedDeleteFunc[K, V]) *TypedCache[K, V] {
+	return &TypedCache[K, V]{
+		maxSize:  maxSize,
+		clock:    SystemClock,
+		items:    make(map[K]*list.Element),
+		ll:       list.New(),
+		onDelete: onDelete,
+	}
+}
+
+// SetSizeFunc 设置计算元素大小的方法, nil 时每个元素大小为1
+// 已有元素按新方法重新计算, 超出限制时按LRU规则淘汰
+func (c *TypedCache[K, V]) SetSizeFunc(fn SizeFunc[K, V]) {
+	c.size = fn
+
+	c.currSize = 0
+	for ele := c.ll.Front(); ele != nil; ele = ele.Next() {
+		var e = ele.Value.(*typedEntity[K, V])
+		e.size = c.sizeOf(e.key, e.val)
+		c.currSize += e.size
+	}
+
+	c.evict()
+}
+
+// SetDefaultTTL 设置 Add 使用的默认过期时长, 0 表示不过期, 只影响之后添加的元素
+func (c *TypedCache[K, V]) SetDefaultTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+// SetDeleteFunc 替换删除时的触发函数
+func (c *TypedCache[K, V]) SetDeleteFunc(onDelete TypedDeleteFunc[K, V]) {
+	c.onDelete = onDelete
+}
+
+// SetClock 设置判断过期使用的时钟
+func (c *TypedCache[K, V]) SetClock(clock Clock) {
+	c.clock = clock
+}
+
+func (c *TypedCache[K, V]) sizeOf(key K, value V) int64 {
+	if c.size == nil {
+		return 1
+	}
+	return c.size(key, value)
+}
+
+// Get 根据key获取元素, 元素已过期时删除并返回不存在
+func (c *TypedCache[K, V]) Get(key K) (V, bool) {
+	var v, _, ok = c.getExpire(key)
+	return v, ok
+}
+
+// getExpire 同 Get, 同时返回过期时间
+func (c *TypedCache[K, V]) getExpire(key K) (V, time.Time, bool) {
+	var zero V
+
+	if ele, ok := c.items[key]; ok {
+		var e = ele.Value.(*typedEntity[K, V])
+		if e.expired(c.clock.Now()) {
+			c.remove(ele, EvictExpired)
+			c.stats.Misses++
+			return zero, time.Time{}, false
+		}
+
+		c.ll.MoveToFront(ele)
+		c.stats.Hits++
+		return e.val, e.expire, true
+	}
+
+	c.stats.Misses++
+	return zero, time.Time{}, false
+}
+
+// Add 添加元素, 使用默认过期时长, 超出限制时按LRU规则淘汰
+func (c *TypedCache[K, V]) Add(key K, value V) {
+	c.AddWithTTL(key, value, c.ttl)
+}
+
+// AddWithTTL 添加元素, {ttl} 后过期, ttl <= 0 时不过期, 超出限制时按LRU规则淘汰
+func (c *TypedCache[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
+	var size = c.sizeOf(key, value)
+
+	var expire time.Time
+	if ttl > 0 {
+		expire = c.clock.Now().Add(ttl)
+	}
+
+	if ele, ok := c.items[key]; ok {
+		c.ll.MoveToFront(ele)
+
+		var e = ele.Value.(*typedEntity[K, V])
+		c.currSize += size - e.size
+
+		e.val = value
+		e.size = size
+		e.expire = expire
+
+		c.stats.Updates++
+	} else {
+		var ele = c.ll.PushFront(&typedEntity[K, V]{
+			key:    key,
+			val:    value,
+			size:   size,
+			expire: expire,
+		})
+
+		c.items[key] = ele
+		c.currSize += size
+
+		c.stats.Adds++
+	}
+
+	c.evict()
+}
+
+// Del 根据key删除元素
+func (c *TypedCache[K, V]) Del(key K) bool {
+	if ele, ok := c.items[key]; ok {
+		c.remove(ele, EvictExplicit)
+		return true
+	}
+	return false
+}
+
+// DelOldest 删除最近最少访问的元素, 即队尾元素
+func (c *TypedCache[K, V]) DelOldest() bool {
+	if ele := c.ll.Back(); ele != nil {
+		c.remove(ele, EvictExplicit)
+		return true
+	}
+	return false
+}
+
+// DelExpired 删除全部过期元素, 返回删除的个数
+func (c *TypedCache[K, V]) DelExpired() int {
+	var now = c.clock.Now()
+
+	var n int
+	for ele := c.ll.Back(); ele != nil; {
+		var prev = ele.Prev()
+		if ele.Value.(*typedEntity[K, V]).expired(now) {
+			c.remove(ele, EvictExpired)
+			n++
+		}
+		ele = prev
+	}
+	return n
+}
+
+// Len 元素个数, 包含尚未删除的过期元素
+func (c *TypedCache[K, V]) Len() int {
+	return c.ll.Len()
+}
+
+// Stats 统计快照
+func (c *TypedCache[K, V]) Stats() Stats {
+	var s = c.stats
+	s.Entries = int64(c.ll.Len())
+	s.Bytes = c.currSize
+	return s
+}
+
+// Size 当前总大小
+func (c *TypedCache[K, V]) Size() int64 {
+	return c.currSize
+}
+
+// evict 超出限制时删除队尾元素
+func (c *TypedCache[K, V]) evict() {
+	for c.maxSize != 0 && c.maxSize < c.currSize && c.ll.Len() > 0 {
+		c.remove(c.ll.Back(), EvictCapacity)
+	}
+}
+
+func (c *TypedCache[K, V]) remove(ele *list.Element, reason EvictReason) {
+	c.ll.Remove(ele)
+
+	var e = ele.Value.(*typedEntity[K, V])
+	delete(c.items, e.key)
+
+	c.currSize -= e.size
+	c.stats.evicted(reason)
+
+	if c.onDelete != nil {
+		c.onDelete(e.key, e.val, reason)
+	}
+}
@@ -0,0 +1,80 @@
+package lruv1_test
+
+import (
+	"testing"
+
+	"github.com/alpha-abc/gokits/lru/lruv1"
+)
+
+func TestTypedCacheCount(t *testing.T) {
+	var deleted []int
+	var reasons []lruv1.EvictReason
+	var cache = lruv1.NewTyped[int, string](2, func(key int, val string, reason lruv1.EvictReason) {
+		deleted = append(deleted, key)
+		reasons = append(reasons, reason)
+	})
+
+	cache.Add(1, "a")
+	cache.Add(2, "b")
+	cache.Get(1)
+	cache.Add(3, "c") // 淘汰 2
+
+	if _, ok := cache.Get(2); ok {
+		t.Fatal("2 not evicted")
+	}
+	if v, ok := cache.Get(1); !ok || v != "a" {
+		t.Fatalf("get 1: %q %v", v, ok)
+	}
+	if cache.Len() != 2 || cache.Size() != 2 {
+		t.Fatalf("len %d size %d", cache.Len(), cache.Size())
+	}
+
+	if !cache.DelOldest() { // 删除 3
+		t.Fatal("del oldest")
+	}
+	if !cache.Del(1) || cache.Del(1) {
+		t.Fatal("del 1")
+	}
+	if cache.DelOldest() {
+		t.Fatal("del oldest on empty cache")
+	}
+
+	if len(deleted) != 3 || deleted[0] != 2 || deleted[1] != 3 || deleted[2] != 1 {
+		t.Fatalf("deleted %v", deleted)
+	}
+	if reasons[0] != lruv1.EvictCapacity || reasons[1] != lruv1.EvictExplicit || reasons[2] != lruv1.EvictExplicit {
+		t.Fatalf("reasons %v", reasons)
+	}
+}
+
+func TestTypedCacheSize(t *testing.T) {
+	var cache = lruv1.NewTyped[string, []byte](10, nil)
+	cache.SetSizeFunc(func(key string, val []byte) int64 {
+		return int64(len(val))
+	})
+
+	cache.Add("a", make([]byte, 4))
+	cache.Add("b", make([]byte, 4))
+	if cache.Size() != 8 {
+		t.Fatalf("size %d", cache.Size())
+	}
+
+	// 更新后超出限制, 淘汰 b
+	cache.Add("a", make([]byte, 7))
+	if _, ok := cache.Get("b"); ok || cache.Size() != 7 {
+		t.Fatalf("size %d", cache.Size())
+	}
+
+	// 超出限制的单个元素不会保留
+	cache.Add("c", make([]byte, 11))
+	if cache.Len() != 0 || cache.Size() != 0 {
+		t.Fatalf("len %d size %d", cache.Len(), cache.Size())
+	}
+
+	// 切换为按个数限制后重新计算
+	cache.Add("a", make([]byte, 7))
+	cache.SetSizeFunc(nil)
+	if cache.Size() != 1 {
+		t.Fatalf("size %d", cache.Size())
+	}
+}
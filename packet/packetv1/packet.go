@@ -145,12 +145,13 @@ func (p *Packet) Pack(version uint64, command uint64, data []byte) ([][]byte, er
 func (p *Packet) Unpack(reader io.Reader) (uint64, uint64, []byte, error) {
 	var headerBs = make([]byte, p.vl+p.cl+p.dl)
 
-	var nr, er = reader.Read(headerBs)
-	if nr != len(headerBs) {
-		return 0, 0, nil, fmt.Errorf("unpack header error, read len: %d, need len: %d", nr, len(headerBs))
+	// 流式读取(如TCP)时单次 Read 可能读不满, 使用 io.ReadFull; 未读到任何数据时返回 io.EOF
+	var nr, er = io.ReadFull(reader, headerBs)
+	if er == io.EOF {
+		return 0, 0, nil, er
 	}
 	if er != nil {
-		return 0, 0, nil, er
+		return 0, 0, nil, fmt.Errorf("unpack header error, read len: %d, need len: %d: %w", nr, len(headerBs), er)
 	}
 
 	var i1 = 0
@@ -163,14 +164,9 @@ func (p *Packet) Unpack(reader io.Reader) (uint64, uint64, []byte, error) {
 	var dl = DecodeUint64(headerBs[i3:i4])
 
 	var dataBs = make([]byte, dl)
-	nr, er = reader.Read(dataBs)
-	if nr != len(dataBs) {
-		return 0, 0, nil, fmt.Errorf("unpack data error, read len: %d, need len: %d", nr, len(headerBs))
-	}
+	nr, er = io.ReadFull(reader, dataBs)
 	if er != nil {
-		if er != io.EOF {
-			return 0, 0, nil, er
-		}
+		return 0, 0, nil, fmt.Errorf("unpack data error, read len: %d, need len: %d: %w", nr, len(dataBs), er)
 	}
 
 	return version, command, dataBs, nil
@@ -2,8 +2,11 @@ package packetv1_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/alpha-abc/gokits/packet/packetv1"
 )
@@ -42,3 +45,42 @@ func Test_PackUnPack(t *testing.T) {
 	_v, _c, _d, _e = p.Unpack(&buff)
 	fmt.Println(_v, _c, len(_d), _e)
 }
+
+func Test_UnpackStream(t *testing.T) {
+	var p, _ = packetv1.NewPacket(1, 1, 2)
+	var bs, _ = p.Pack(3, 4, []byte("hello"))
+
+	var buff bytes.Buffer
+	for _, b := range bs {
+		buff.Write(b)
+	}
+	var full = buff.Bytes()
+
+	// 流式读取, 每次只读到一个字节
+	var v, c, d, err = p.Unpack(iotest.OneByteReader(bytes.NewReader(full)))
+	if err != nil || v != 3 || c != 4 || string(d) != "hello" {
+		t.Fatalf("one byte reader: %d %d %q %v", v, c, d, err)
+	}
+
+	// 没有数据
+	if _, _, _, err = p.Unpack(bytes.NewReader(nil)); err != io.EOF {
+		t.Fatalf("empty: %v", err)
+	}
+
+	// 头部不完整
+	if _, _, _, err = p.Unpack(bytes.NewReader(full[:2])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("truncated header: %v", err)
+	}
+
+	// 数据不完整
+	if _, _, _, err = p.Unpack(bytes.NewReader(full[:len(full)-1])); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("truncated data: %v", err)
+	}
+
+	// 保留底层错误 (如读超时)
+	var timeout = errors.New("i/o timeout")
+	var r = io.MultiReader(bytes.NewReader(full[:5]), iotest.ErrReader(timeout))
+	if _, _, _, err = p.Unpack(r); !errors.Is(err, timeout) {
+		t.Fatalf("read error: %v", err)
+	}
+}
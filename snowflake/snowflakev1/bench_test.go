@@ -0,0 +1,82 @@
+package snowflakev1_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+// mutexNode 旧版实现: 互斥锁 + 序列号用尽时忙等, 仅用于基准对比
+type mutexNode struct {
+	mux sync.Mutex
+
+	epoch             int64
+	relativeTimestamp int64
+	nodeID            int64
+	sequence          int64
+}
+
+func (n *mutexNode) Generate() (snowflakev1.ID, error) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	var now = time.Now().UnixNano() / 1e6
+	var nodeTime = n.relativeTimestamp + n.epoch
+
+	if now < nodeTime {
+		return 0, fmt.Errorf("clock(%d) move backwards, reject generate until %d", now, nodeTime)
+	}
+
+	if now == nodeTime {
+		n.sequence = (n.sequence + 1) & (-1 ^ (-1 << 10))
+
+		if n.sequence == 0 {
+			for now <= nodeTime {
+				now = time.Now().UnixNano() / 1e6
+			}
+		}
+	} else {
+		n.sequence = 0
+	}
+
+	n.relativeTimestamp = now - n.epoch
+
+	return snowflakev1.ID((n.relativeTimestamp << 20) | (n.nodeID << 10) | n.sequence), nil
+}
+
+func BenchmarkGenerateParallel(b *testing.B) {
+	var node, _ = snowflakev1.NewNode(1)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var _, _ = node.Generate()
+		}
+	})
+}
+
+func BenchmarkGenerateNParallel(b *testing.B) {
+	var node, _ = snowflakev1.NewNode(1)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var _, _ = node.GenerateN(64)
+		}
+	})
+}
+
+func BenchmarkMutexGenerateParallel(b *testing.B) {
+	var node = &mutexNode{
+		epoch:             snowflakev1.Epoch,
+		relativeTimestamp: time.Now().UnixNano()/1e6 - snowflakev1.Epoch,
+		nodeID:            1,
+	}
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var _, _ = node.Generate()
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package snowflakev1
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock 时钟, 可在测试中替换
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// SystemClock 系统时钟
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// ErrClockBackwards 时钟回拨, 可通过 errors.Is 判断
+var ErrClockBackwards = errors.New("clock move backwards")
+
+// RollbackStrategy 时钟回拨时的处理策略
+type RollbackStrategy int
+
+const (
+	// RollbackFail 立即返回错误 (默认)
+	RollbackFail RollbackStrategy = iota
+
+	// RollbackWait 等待时钟追上上次生成ID的时间, 回拨超过上限时返回错误
+	RollbackWait
+
+	// RollbackLogical 逻辑时钟: 沿用上次生成ID的时间继续递增序列号, 序列号用尽时借用下一时间单位,
+	// 保证ID单调递增且不等待; 时间领先系统时钟超过上限时返回错误
+	RollbackLogical
+)
+
+func (s RollbackStrategy) String() string {
+	switch s {
+	case RollbackFail:
+		return "fail"
+	case RollbackWait:
+		return "wait"
+	case RollbackLogical:
+		return "logical"
+	}
+	return "unknown"
+}
@@ -0,0 +1,150 @@
+package snowflakev1_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+// fakeClock 手动控制的时钟, Sleep 直接推进时间
+type fakeClock struct {
+	mux sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.Add(d)
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestNode(t *testing.T, clock *fakeClock, strategy snowflakev1.RollbackStrategy, max time.Duration) *snowflakev1.Node {
+	var node, err = snowflakev1.NewNode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.SetClock(clock)
+	node.SetRollback(strategy, max)
+	return node
+}
+
+func TestRollbackFail(t *testing.T) {
+	var clock = newFakeClock()
+	var node = newTestNode(t, clock, snowflakev1.RollbackFail, 0)
+
+	clock.Add(time.Millisecond)
+	if _, err := node.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Add(-5 * time.Millisecond)
+	if _, err := node.Generate(); !errors.Is(err, snowflakev1.ErrClockBackwards) {
+		t.Fatalf("expect clock backwards error, got %v", err)
+	}
+}
+
+func TestRollbackWait(t *testing.T) {
+	var clock = newFakeClock()
+	var node = newTestNode(t, clock, snowflakev1.RollbackWait, 10*time.Millisecond)
+
+	clock.Add(time.Millisecond)
+	var id1, err = node.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Add(-5 * time.Millisecond)
+	var start = clock.Now()
+	id2, err := node.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id2 <= id1 {
+		t.Fatalf("id not increasing: %d <= %d", id2, id1)
+	}
+	if waited := clock.Now().Sub(start); waited != 5*time.Millisecond {
+		t.Fatalf("expect wait 5ms, got %s", waited)
+	}
+
+	clock.Add(-time.Second)
+	if _, err := node.Generate(); !errors.Is(err, snowflakev1.ErrClockBackwards) {
+		t.Fatalf("expect clock backwards error beyond bound, got %v", err)
+	}
+}
+
+func TestRollbackLogical(t *testing.T) {
+	var clock = newFakeClock()
+	var node = newTestNode(t, clock, snowflakev1.RollbackLogical, time.Second)
+
+	clock.Add(time.Millisecond)
+	var last, err = node.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Add(-100 * time.Millisecond)
+
+	// 超过一个时间单位的序列号容量, 时钟不前进也不阻塞
+	for i := 0; i < 3000; i++ {
+		var id, err = node.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= last {
+			t.Fatalf("id not increasing: %d <= %d", id, last)
+		}
+		last = id
+	}
+
+	clock.Add(-2 * time.Second)
+	if _, err := node.Generate(); !errors.Is(err, snowflakev1.ErrClockBackwards) {
+		t.Fatalf("expect clock backwards error beyond bound, got %v", err)
+	}
+}
+
+func TestGenerateN(t *testing.T) {
+	var clock = newFakeClock()
+	var node = newTestNode(t, clock, snowflakev1.RollbackFail, 0)
+
+	if _, err := node.GenerateN(1025); err == nil {
+		t.Fatal("expect invalid count error")
+	}
+
+	var first, err = node.GenerateN(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 当前时间单位剩余不足, 休眠至下一时间单位
+	var before = clock.Now()
+	second, err := node.GenerateN(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clock.Now().After(before) {
+		t.Fatal("expect wait for next time unit")
+	}
+
+	var _, t1, _, s1 = node.Extract(first)
+	var _, t2, _, s2 = node.Extract(second)
+	if s1 != 1 || s2 != 0 || t2 != t1+1 {
+		t.Fatalf("unexpected range: (%d, %d) (%d, %d)", t1, s1, t2, s2)
+	}
+}
@@ -0,0 +1,85 @@
+package snowflakev1
+
+import "time"
+
+// Decoded ID 按布局拆分后的各部分
+type Decoded struct {
+	ID       ID
+	Time     time.Time // 生成ID时的时间, 精度为 Layout.TimeUnit
+	NodeID   int64
+	Sequence int64
+}
+
+// Decode 按 {layout} 拆分ID, 应传入生成该ID的节点的布局 (Node.Layout)
+func (id ID) Decode(layout Layout) Decoded {
+	return layout.Decode(id)
+}
+
+// Decode 按布局拆分ID
+func (l Layout) Decode(id ID) Decoded {
+	var i = int64(id)
+
+	var relative = (i >> (l.NodeBits + l.SequenceBits)) & l.MaxTime()
+
+	return Decoded{
+		ID:       id,
+		Time:     time.Unix(0, (l.epochUnits()+relative)*int64(l.TimeUnit)),
+		NodeID:   (i >> l.SequenceBits) & l.MaxNodeID(),
+		Sequence: i & l.MaxSequence(),
+	}
+}
+
+// MinID 时间 {t} 所在时间单位内可能生成的最小ID, 节点ID与序列号均为0
+// {t} 早于 Epoch 时返回 Epoch 处的最小ID, 超出可表示范围时返回最大时间处的最小ID
+//
+// 按时间范围查询: id >= MinID(from) AND id <= MaxID(to)
+func (l Layout) MinID(t time.Time) ID {
+	return ID(l.relative(t) << (l.NodeBits + l.SequenceBits))
+}
+
+// MaxID 时间 {t} 所在时间单位内可能生成的最大ID, 节点ID与序列号均取最大值
+// {t} 早于 Epoch 时返回 Epoch 处的最大ID, 超出可表示范围时返回最大时间处的最大ID
+func (l Layout) MaxID(t time.Time) ID {
+	return ID(l.relative(t)<<(l.NodeBits+l.SequenceBits) |
+		l.MaxNodeID()<<l.SequenceBits |
+		l.MaxSequence())
+}
+
+// relative {t} 相对 Epoch 的时间戳, 限制在 [0, MaxTime] 范围内
+func (l Layout) relative(t time.Time) int64 {
+	// 以对齐到时间单位的 Epoch 为起点, 与节点生成ID时的截断方式一致; Sub 溢出时取极值, 不会回绕
+	var epoch = time.Unix(0, l.epochUnits()*int64(l.TimeUnit))
+	var relative = int64(t.Sub(epoch) / l.TimeUnit)
+	if relative < 0 {
+		return 0
+	}
+	if relative > l.MaxTime() {
+		return l.MaxTime()
+	}
+	return relative
+}
+
+// Decode 按节点的布局拆分ID
+func (n *Node) Decode(id ID) Decoded {
+	return n.layout.Decode(id)
+}
+
+// MinID 按节点的布局返回时间 {t} 对应的最小ID
+func (n *Node) MinID(t time.Time) ID {
+	return n.layout.MinID(t)
+}
+
+// MaxID 按节点的布局返回时间 {t} 对应的最大ID
+func (n *Node) MaxID(t time.Time) ID {
+	return n.layout.MaxID(t)
+}
+
+// MinID 按默认布局返回时间 {t} 对应的最小ID
+func MinID(t time.Time) ID {
+	return DefaultLayout().MinID(t)
+}
+
+// MaxID 按默认布局返回时间 {t} 对应的最大ID
+func MaxID(t time.Time) ID {
+	return DefaultLayout().MaxID(t)
+}
@@ -0,0 +1,74 @@
+package snowflakev1_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+func TestDecode(t *testing.T) {
+	var layout = snowflakev1.Layout{
+		Epoch:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		TimeUnit:     10 * time.Millisecond,
+		TimeBits:     39,
+		NodeBits:     16,
+		SequenceBits: 8,
+	}
+
+	var node, err = snowflakev1.NewNodeWithLayout(300, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var before = time.Now().Truncate(layout.TimeUnit)
+	var id, _ = node.Generate()
+	var after = time.Now()
+
+	var d = id.Decode(node.Layout())
+	if d.ID != id || d.NodeID != 300 {
+		t.Fatalf("decoded: %+v", d)
+	}
+	if d.Time.Before(before) || d.Time.After(after) {
+		t.Fatalf("decoded time %s not in [%s, %s]", d.Time, before, after)
+	}
+	if d != node.Decode(id) {
+		t.Fatalf("node decode mismatch")
+	}
+
+	var min, max = node.MinID(d.Time), node.MaxID(d.Time)
+	if id < min || id > max {
+		t.Fatalf("id %d not in [%d, %d]", id, min, max)
+	}
+	if min.Decode(layout).Time != d.Time || max.Decode(layout).Time != d.Time {
+		t.Fatalf("range bounds cross time unit")
+	}
+
+	// 相邻时间单位不重叠
+	var next = d.Time.Add(layout.TimeUnit)
+	if node.MinID(next) != max+1 {
+		t.Fatalf("min of next unit %d, want %d", node.MinID(next), max+1)
+	}
+
+	// 越界时间截断
+	if layout.MinID(layout.Epoch.Add(-time.Hour)) != 0 {
+		t.Fatal("time before epoch")
+	}
+	if layout.MaxID(time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC)) != 1<<63-1 {
+		t.Fatal("time after max")
+	}
+}
+
+func TestDefaultMinMaxID(t *testing.T) {
+	var node, _ = snowflakev1.NewNode(1)
+	var id, _ = node.Generate()
+	var d = id.Decode(snowflakev1.DefaultLayout())
+
+	var _, ms, _, _ = snowflakev1.Extract(id)
+	if d.Time.UnixNano()/int64(time.Millisecond) != ms {
+		t.Fatalf("decode time %s, extract %d", d.Time, ms)
+	}
+	if id < snowflakev1.MinID(d.Time) || id > snowflakev1.MaxID(d.Time) {
+		t.Fatal("id out of range")
+	}
+}
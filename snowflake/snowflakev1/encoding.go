@@ -0,0 +1,253 @@
+package snowflakev1
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidID 无法解析的ID, 可通过 errors.Is 判断
+var ErrInvalidID = errors.New("invalid id")
+
+const (
+	// base32Alphabet Crockford base32 字母表, 不含 I L O U
+	base32Alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+	// base58Alphabet 比特币 base58 字母表, 不含 0 O I l
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+var (
+	base32Decode = decodeMap(base32Alphabet)
+	base58Decode = decodeMap(base58Alphabet)
+)
+
+func init() {
+	// Crockford: 解析时不区分大小写, I L 视为 1, O 视为 0
+	for i := 0; i < len(base32Alphabet); i++ {
+		var c = base32Alphabet[i]
+		if c >= 'A' && c <= 'Z' {
+			base32Decode[c+'a'-'A'] = base32Decode[c]
+		}
+	}
+	for _, c := range "IiLl" {
+		base32Decode[c] = 1
+	}
+	for _, c := range "Oo" {
+		base32Decode[c] = 0
+	}
+}
+
+// decodeMap 字符到数值的映射, 不在字母表中的字符为 0xFF
+func decodeMap(alphabet string) [256]byte {
+	var m [256]byte
+	for i := range m {
+		m[i] = 0xFF
+	}
+	for i := 0; i < len(alphabet); i++ {
+		m[alphabet[i]] = byte(i)
+	}
+	return m
+}
+
+// Int64 返回 int64 形式的ID
+func (id ID) Int64() int64 {
+	return int64(id)
+}
+
+// String 返回十进制字符串
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// Base2 返回二进制字符串
+func (id ID) Base2() string {
+	return strconv.FormatInt(int64(id), 2)
+}
+
+// Base32 返回 Crockford base32 字符串
+func (id ID) Base32() string {
+	return encode(uint64(id), base32Alphabet)
+}
+
+// Base36 返回 base36 字符串 (小写)
+func (id ID) Base36() string {
+	return strconv.FormatInt(int64(id), 36)
+}
+
+// Base58 返回 base58 字符串
+func (id ID) Base58() string {
+	return encode(uint64(id), base58Alphabet)
+}
+
+// Base64 返回 ID 大端8字节的 base64-url 字符串 (无填充, 定长11位)
+func (id ID) Base64() string {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(id))
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// encode 按字母表编码, 高位在前
+func encode(v uint64, alphabet string) string {
+	if v == 0 {
+		return alphabet[:1]
+	}
+
+	var base = uint64(len(alphabet))
+	var buf [64]byte
+	var i = len(buf)
+	for v > 0 {
+		i--
+		buf[i] = alphabet[v%base]
+		v /= base
+	}
+	return string(buf[i:])
+}
+
+// decode 按字母表解码, 溢出 int64 时返回错误
+func decode(s string, m *[256]byte, base uint64, name string) (ID, error) {
+	if s == "" {
+		return 0, fmt.Errorf("%w: empty %s string", ErrInvalidID, name)
+	}
+
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		var d = m[s[i]]
+		if d == 0xFF {
+			return 0, fmt.Errorf("%w: %s string %q has invalid char %q", ErrInvalidID, name, s, s[i])
+		}
+
+		if v > (1<<63-1-uint64(d))/base {
+			return 0, fmt.Errorf("%w: %s string %q overflow", ErrInvalidID, name, s)
+		}
+		v = v*base + uint64(d)
+	}
+	return ID(v), nil
+}
+
+// parseInt 以 strconv 解析, 统一错误
+func parseInt(s string, base int, name string) (ID, error) {
+	var i, err = strconv.ParseInt(s, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s string %q: %v", ErrInvalidID, name, s, err)
+	}
+	return ID(i), nil
+}
+
+// ParseInt64 由 int64 转换为ID
+func ParseInt64(i int64) ID {
+	return ID(i)
+}
+
+// ParseString 解析十进制字符串
+func ParseString(s string) (ID, error) {
+	return parseInt(s, 10, "base10")
+}
+
+// ParseBase2 解析二进制字符串
+func ParseBase2(s string) (ID, error) {
+	return parseInt(s, 2, "base2")
+}
+
+// ParseBase32 解析 Crockford base32 字符串, 不区分大小写, I L 视为 1, O 视为 0
+func ParseBase32(s string) (ID, error) {
+	return decode(s, &base32Decode, 32, "base32")
+}
+
+// ParseBase36 解析 base36 字符串, 不区分大小写
+func ParseBase36(s string) (ID, error) {
+	return parseInt(s, 36, "base36")
+}
+
+// ParseBase58 解析 base58 字符串
+func ParseBase58(s string) (ID, error) {
+	return decode(s, &base58Decode, 58, "base58")
+}
+
+// ParseBase64 解析 Base64 返回的 base64-url 字符串
+func ParseBase64(s string) (ID, error) {
+	var b, err = base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: base64 string %q: %v", ErrInvalidID, s, err)
+	}
+	if len(b) != 8 {
+		return 0, fmt.Errorf("%w: base64 string %q decode %d bytes, require 8", ErrInvalidID, s, len(b))
+	}
+	return ID(binary.BigEndian.Uint64(b)), nil
+}
+
+// MarshalJSON 序列化为十进制字符串, 避免 JavaScript 等客户端丢失精度
+func (id ID) MarshalJSON() ([]byte, error) {
+	var b = make([]byte, 0, 22)
+	b = append(b, '"')
+	b = strconv.AppendInt(b, int64(id), 10)
+	b = append(b, '"')
+	return b, nil
+}
+
+// UnmarshalJSON 反序列化, 接受十进制字符串或数字, null 时不做修改
+func (id *ID) UnmarshalJSON(b []byte) error {
+	var s = string(b)
+	if s == "null" {
+		return nil
+	}
+
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	var v, err = ParseString(s)
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
+
+// MarshalText 序列化为十进制字符串, 用于 map key 等场景
+func (id ID) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(id), 10), nil
+}
+
+// UnmarshalText 解析十进制字符串
+func (id *ID) UnmarshalText(b []byte) error {
+	var v, err = ParseString(string(b))
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
+
+// Value 实现 driver.Valuer, 以 int64 存储
+func (id ID) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+
+// Scan 实现 sql.Scanner, 接受整数及十进制字符串
+func (id *ID) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case int64:
+		*id = ID(v)
+	case []byte:
+		var i, err = ParseString(string(v))
+		if err != nil {
+			return err
+		}
+		*id = i
+	case string:
+		var i, err = ParseString(v)
+		if err != nil {
+			return err
+		}
+		*id = i
+	case nil:
+		return fmt.Errorf("%w: scan nil into ID", ErrInvalidID)
+	default:
+		return fmt.Errorf("%w: unsupported scan type %T", ErrInvalidID, src)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package snowflakev1_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+func TestEncoding(t *testing.T) {
+	var node, err = snowflakev1.NewNode(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids = []snowflakev1.ID{0, 1, 57, 58, 1<<63 - 1}
+	for i := 0; i < 10; i++ {
+		var id, err = node.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	type codec struct {
+		name   string
+		encode func(snowflakev1.ID) string
+		parse  func(string) (snowflakev1.ID, error)
+	}
+	var codecs = []codec{
+		{"base10", snowflakev1.ID.String, snowflakev1.ParseString},
+		{"base2", snowflakev1.ID.Base2, snowflakev1.ParseBase2},
+		{"base32", snowflakev1.ID.Base32, snowflakev1.ParseBase32},
+		{"base36", snowflakev1.ID.Base36, snowflakev1.ParseBase36},
+		{"base58", snowflakev1.ID.Base58, snowflakev1.ParseBase58},
+		{"base64", snowflakev1.ID.Base64, snowflakev1.ParseBase64},
+	}
+
+	for _, c := range codecs {
+		for _, id := range ids {
+			var s = c.encode(id)
+			var got, err = c.parse(s)
+			if err != nil {
+				t.Fatalf("%s parse %q: %v", c.name, s, err)
+			}
+			if got != id {
+				t.Fatalf("%s round trip %d -> %q -> %d", c.name, id, s, got)
+			}
+		}
+	}
+
+	// Crockford: 不区分大小写, I L -> 1, O -> 0
+	var id, _ = snowflakev1.ParseBase32("1o")
+	if want, _ := snowflakev1.ParseBase32("10"); id != want {
+		t.Fatalf("base32 alias: %d != %d", id, want)
+	}
+	id, _ = snowflakev1.ParseBase32("lI")
+	if want, _ := snowflakev1.ParseBase32("11"); id != want {
+		t.Fatalf("base32 alias: %d != %d", id, want)
+	}
+
+	var bad = []func() error{
+		func() error { _, err := snowflakev1.ParseBase58("0"); return err },
+		func() error { _, err := snowflakev1.ParseBase32("U"); return err },
+		func() error { _, err := snowflakev1.ParseBase32("ZZZZZZZZZZZZZZ"); return err },
+		func() error { _, err := snowflakev1.ParseBase64("AAAA"); return err },
+		func() error { _, err := snowflakev1.ParseString(""); return err },
+	}
+	for i, fn := range bad {
+		if err := fn(); !errors.Is(err, snowflakev1.ErrInvalidID) {
+			t.Fatalf("case %d: expect ErrInvalidID, got %v", i, err)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type model struct {
+		ID snowflakev1.ID `json:"id"`
+	}
+
+	var b, err = json.Marshal(model{ID: 1<<62 + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"id":"4611686018427387905"}` {
+		t.Fatalf("marshal: %s", b)
+	}
+
+	for _, s := range []string{`{"id":"4611686018427387905"}`, `{"id":4611686018427387905}`} {
+		var m model
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			t.Fatal(err)
+		}
+		if m.ID != 1<<62+1 {
+			t.Fatalf("unmarshal %s: %d", s, m.ID)
+		}
+	}
+
+	var m model
+	if err := json.Unmarshal([]byte(`{"id":"abc"}`), &m); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestSQL(t *testing.T) {
+	var id snowflakev1.ID = 123456789
+
+	var v, err = id.Value()
+	if err != nil || v != int64(123456789) {
+		t.Fatalf("value: %v %v", v, err)
+	}
+
+	for _, src := range []interface{}{int64(123456789), "123456789", []byte("123456789")} {
+		var got snowflakev1.ID
+		if err := got.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if got != id {
+			t.Fatalf("scan %T: %d", src, got)
+		}
+	}
+
+	var got snowflakev1.ID
+	if err := got.Scan(1.5); err == nil {
+		t.Fatal("expect error")
+	}
+}
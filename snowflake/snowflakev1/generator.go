@@ -0,0 +1,216 @@
+package snowflakev1
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Generator 按生成顺序递增的ID生成器, 不同ID方案 (Node, ULIDGenerator, UUIDGenerator) 可通过配置互相替换
+// 同一生成器生成的ID单调递增: ULID/UUID 的字符串按字典序递增, snowflake 的十进制字符串按数值递增
+type Generator interface {
+	// NextID 生成一个ID, 返回其字符串形式
+	NextID() (string, error)
+
+	// Time 解析 NextID 返回的字符串, 返回生成ID时的时间
+	Time(id string) (time.Time, error)
+}
+
+var (
+	_ Generator = (*Node)(nil)
+	_ Generator = (*ULIDGenerator)(nil)
+	_ Generator = (*UUIDGenerator)(nil)
+)
+
+// NextID 生成一个ID, 返回十进制字符串
+func (n *Node) NextID() (string, error) {
+	var id, err = n.Generate()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// Time 解析十进制字符串形式的ID, 按节点的布局返回生成时间
+func (n *Node) Time(id string) (time.Time, error) {
+	var i, err = ParseString(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return n.layout.Decode(i).Time, nil
+}
+
+// monotonic 毫秒时间戳 + 随机数的单调生成器, ULID 与 UUIDv7 共用, 并发安全
+// 同一毫秒内在上次的随机数上加1, 保证单调递增; 随机数用尽时等待下一毫秒 (RollbackLogical 时借用下一毫秒)
+// 随机数 = hi<<loBits | lo, 共 hiBits+loBits 位
+type monotonic struct {
+	mux sync.Mutex
+
+	clock       Clock
+	rollback    RollbackStrategy
+	maxRollback time.Duration
+	entropy     io.Reader
+
+	hiBits, loBits uint
+
+	last   int64 // 上次生成ID的毫秒时间戳
+	hi, lo uint64
+}
+
+func newMonotonic(hiBits uint, loBits uint) monotonic {
+	return monotonic{
+		clock:    SystemClock,
+		rollback: RollbackFail,
+		entropy:  rand.Reader,
+		hiBits:   hiBits,
+		loBits:   loBits,
+	}
+}
+
+// SetClock 设置时钟, 需在生成ID之前调用
+func (m *monotonic) SetClock(clock Clock) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.clock = clock
+	m.last = 0
+}
+
+// SetRollback 设置时钟回拨策略, 含义同 Node.SetRollback, 时间单位为毫秒
+func (m *monotonic) SetRollback(strategy RollbackStrategy, max time.Duration) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.rollback = strategy
+	m.maxRollback = max
+}
+
+// SetEntropy 设置随机数来源, 默认 crypto/rand.Reader; 非并发安全的来源(如 math/rand.Rand)也可使用
+func (m *monotonic) SetEntropy(entropy io.Reader) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.entropy = entropy
+}
+
+// now 当前毫秒时间戳
+func (m *monotonic) now() int64 {
+	return m.clock.Now().UnixNano() / int64(time.Millisecond)
+}
+
+// next 返回毫秒时间戳与随机数
+func (m *monotonic) next() (int64, uint64, uint64, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	for {
+		var now = m.now()
+		if now < m.last {
+			var t, err = m.tolerate(now)
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			now = t
+		}
+
+		if now == m.last {
+			if m.increment() {
+				return m.last, m.hi, m.lo, nil
+			}
+
+			if m.rollback != RollbackLogical {
+				// 等待下一毫秒
+				m.clock.Sleep(time.Duration(m.last+1)*time.Millisecond - time.Duration(m.clock.Now().UnixNano()))
+				continue
+			}
+
+			// 借用下一毫秒
+			now = m.last + 1
+		}
+
+		if err := m.random(); err != nil {
+			return 0, 0, 0, err
+		}
+		m.last = now
+		return m.last, m.hi, m.lo, nil
+	}
+}
+
+// increment 随机数加1, 溢出时返回 false
+func (m *monotonic) increment() bool {
+	var loMask, hiMask = mask(m.loBits), mask(m.hiBits)
+
+	var lo = (m.lo + 1) & loMask
+	var hi = m.hi
+	if lo == 0 {
+		hi = (hi + 1) & hiMask
+		if hi == 0 {
+			return false
+		}
+	}
+
+	m.hi, m.lo = hi, lo
+	return true
+}
+
+// random 重新生成随机数
+func (m *monotonic) random() error {
+	var b [16]byte
+	if _, err := io.ReadFull(m.entropy, b[:]); err != nil {
+		return fmt.Errorf("read entropy error: %w", err)
+	}
+
+	m.hi = binary.BigEndian.Uint64(b[:8]) & mask(m.hiBits)
+	m.lo = binary.BigEndian.Uint64(b[8:]) & mask(m.loBits)
+	return nil
+}
+
+// tolerate 按回拨策略处理 now < m.last 的情况, 返回可使用的毫秒时间戳
+func (m *monotonic) tolerate(now int64) (int64, error) {
+	var back = time.Duration(m.last-now) * time.Millisecond
+
+	switch m.rollback {
+	case RollbackWait:
+		if back > m.maxRollback {
+			break
+		}
+
+		m.clock.Sleep(back)
+		if now = m.now(); now >= m.last {
+			return now, nil
+		}
+	case RollbackLogical:
+		if m.maxRollback == 0 || back <= m.maxRollback {
+			return m.last, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: clock(%d) behind %d by %s, strategy %s", ErrClockBackwards, now, m.last, back, m.rollback)
+}
+
+// mask 低 {bits} 位全为1
+func mask(bits uint) uint64 {
+	if bits >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<bits - 1
+}
+
+// putTimestamp 以大端6字节写入毫秒时间戳
+func putTimestamp(b []byte, ms int64) {
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+}
+
+// timestamp 读取大端6字节的毫秒时间戳
+func timestamp(b []byte) int64 {
+	return int64(b[0])<<40 | int64(b[1])<<32 | int64(b[2])<<24 |
+		int64(b[3])<<16 | int64(b[4])<<8 | int64(b[5])
+}
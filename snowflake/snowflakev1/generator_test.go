@@ -0,0 +1,159 @@
+package snowflakev1_test
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+// less 按各方案的排序方式比较: snowflake 按数值, ULID/UUID 按字典序
+func less(a string, b string) bool {
+	var x, err1 = strconv.ParseInt(a, 10, 64)
+	var y, err2 = strconv.ParseInt(b, 10, 64)
+	if err1 == nil && err2 == nil {
+		return x < y
+	}
+	return a < b
+}
+
+func TestGenerators(t *testing.T) {
+	var clock = newFakeClock()
+
+	var node = newTestNode(t, clock, snowflakev1.RollbackFail, 0)
+	var ulid = snowflakev1.NewULIDGenerator()
+	ulid.SetClock(clock)
+	var uuid = snowflakev1.NewUUIDGenerator()
+	uuid.SetClock(clock)
+
+	var generators = map[string]snowflakev1.Generator{
+		"snowflake": node,
+		"ulid":      ulid,
+		"uuid":      uuid,
+	}
+
+	for name, g := range generators {
+		var prev string
+		for i := 0; i < 3000; i++ {
+			if i%1000 == 0 {
+				clock.Add(time.Millisecond)
+			}
+
+			var id, err = g.NextID()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if prev != "" && !less(prev, id) {
+				t.Fatalf("%s: %s not greater than %s", name, id, prev)
+			}
+			prev = id
+
+			tm, err := g.Time(id)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !tm.Equal(clock.Now().Truncate(time.Millisecond)) {
+				t.Fatalf("%s: time %s, want %s", name, tm, clock.Now())
+			}
+		}
+	}
+}
+
+func TestMonotonicOverflow(t *testing.T) {
+	var clock = newFakeClock()
+
+	var g = snowflakev1.NewULIDGenerator()
+	g.SetClock(clock)
+	g.SetEntropy(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 64)))
+
+	var a, _ = g.Generate()
+	var b, err = g.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 随机数已是最大值, 同一毫秒内无法递增, 等待下一毫秒
+	if b.Timestamp() != a.Timestamp()+1 || b.String() <= a.String() {
+		t.Fatalf("a %s(%d), b %s(%d)", a, a.Timestamp(), b, b.Timestamp())
+	}
+}
+
+func TestMonotonicRollback(t *testing.T) {
+	var clock = newFakeClock()
+
+	var g = snowflakev1.NewUUIDGenerator()
+	g.SetClock(clock)
+
+	var a, _ = g.Generate()
+	clock.Add(-5 * time.Millisecond)
+
+	if _, err := g.Generate(); !errors.Is(err, snowflakev1.ErrClockBackwards) {
+		t.Fatalf("expect clock backwards, got %v", err)
+	}
+
+	g.SetRollback(snowflakev1.RollbackLogical, 0)
+	var b, err = g.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Timestamp() != a.Timestamp() || b.String() <= a.String() {
+		t.Fatalf("a %s, b %s", a, b)
+	}
+}
+
+func TestParseULID(t *testing.T) {
+	var u, err = snowflakev1.ParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Timestamp() != 1469922850259 {
+		t.Fatalf("timestamp %d", u.Timestamp())
+	}
+	if u.String() != "01ARZ3NDEKTSV4RRFFQ69G5FAV" {
+		t.Fatalf("string %s", u)
+	}
+
+	lower, err := snowflakev1.ParseULID("01arz3ndektsv4rrffq69g5fav")
+	if err != nil || lower != u {
+		t.Fatalf("lower case: %s %v", lower, err)
+	}
+
+	for _, s := range []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FA", "01ARZ3NDEKTSV4RRFFQ69G5FAU", "80000000000000000000000000"} {
+		if _, err := snowflakev1.ParseULID(s); !errors.Is(err, snowflakev1.ErrInvalidID) {
+			t.Fatalf("%q: expect ErrInvalidID, got %v", s, err)
+		}
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	var u, err = snowflakev1.ParseUUID("017F22E2-79B0-7CC3-98C4-DC0C0C07398F")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Version() != 7 || u.Timestamp() != 0x017F22E279B0 {
+		t.Fatalf("version %d timestamp %d", u.Version(), u.Timestamp())
+	}
+	if u.String() != "017f22e2-79b0-7cc3-98c4-dc0c0c07398f" {
+		t.Fatalf("string %s", u)
+	}
+
+	compact, err := snowflakev1.ParseUUID("017f22e279b07cc398c4dc0c0c07398f")
+	if err != nil || compact != u {
+		t.Fatalf("compact: %s %v", compact, err)
+	}
+
+	for _, s := range []string{
+		"",
+		"017f22e2-79b0-4cc3-98c4-dc0c0c07398f", // v4
+		"017f22e2-79b0-7cc3-c8c4-dc0c0c07398f", // 变体错误
+		"017f22e2_79b0_7cc3_98c4_dc0c0c07398f",
+		"017f22e2-79b0-7cc3-98c4-dc0c0c07398g",
+	} {
+		if _, err := snowflakev1.ParseUUID(s); !errors.Is(err, snowflakev1.ErrInvalidID) {
+			t.Fatalf("%q: expect ErrInvalidID, got %v", s, err)
+		}
+	}
+}
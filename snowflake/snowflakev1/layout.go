@@ -0,0 +1,92 @@
+package snowflakev1
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Layout ID的比特位布局, head固定占用1bit(符号位, 恒为0)
+//
+//	[head: 1bit] [time: TimeBits] [node: NodeBits] [sequence: SequenceBits]
+//
+// TimeBits + NodeBits + SequenceBits 不能超过63
+type Layout struct {
+	Epoch        time.Time     // 起始时间
+	TimeUnit     time.Duration // 时间戳单位, 如 time.Millisecond
+	TimeBits     uint8         // time占用比特位数 (相对Epoch存储的时间戳)
+	NodeBits     uint8         // node占用比特位数
+	SequenceBits uint8         // sequence占用比特位数
+}
+
+// DefaultLayout 由包级变量 Epoch, timeBitLen, nodeBitLen, sequenceBitLen 构成的默认布局
+func DefaultLayout() Layout {
+	return Layout{
+		Epoch:        time.Unix(0, Epoch*int64(time.Millisecond)),
+		TimeUnit:     time.Millisecond,
+		TimeBits:     timeBitLen,
+		NodeBits:     nodeBitLen,
+		SequenceBits: sequenceBitLen,
+	}
+}
+
+// minEpoch, maxEpoch UnixNano 可表示的时间范围
+var minEpoch, maxEpoch = time.Unix(0, math.MinInt64), time.Unix(0, math.MaxInt64)
+
+// Validate 校验布局, Epoch 不能为零值, 不能晚于当前时间, 且需在 UnixNano 可表示的范围内
+func (l Layout) Validate() error {
+	if l.Epoch.IsZero() {
+		return fmt.Errorf("epoch is zero")
+	}
+	if l.Epoch.Before(minEpoch) || l.Epoch.After(maxEpoch) {
+		return fmt.Errorf("epoch(%s) out of range [%s, %s]", l.Epoch, minEpoch, maxEpoch)
+	}
+	if l.Epoch.After(time.Now()) {
+		return fmt.Errorf("epoch(%s) is after now", l.Epoch)
+	}
+	if l.TimeUnit <= 0 {
+		return fmt.Errorf("invalid time unit: %s", l.TimeUnit)
+	}
+	if l.TimeBits == 0 {
+		return fmt.Errorf("time bits require greater than 0")
+	}
+	if int(l.TimeBits)+int(l.NodeBits)+int(l.SequenceBits) > 63 {
+		return fmt.Errorf("time bits(%d) + node bits(%d) + sequence bits(%d) greater than 63",
+			l.TimeBits, l.NodeBits, l.SequenceBits)
+	}
+	return nil
+}
+
+// MaxNodeID bit位数为{NodeBits}时表示的最大节点ID
+func (l Layout) MaxNodeID() int64 {
+	return -1 ^ (-1 << l.NodeBits)
+}
+
+// MaxSequence bit位数为{SequenceBits}时表示的最大序列号
+func (l Layout) MaxSequence() int64 {
+	return -1 ^ (-1 << l.SequenceBits)
+}
+
+// MaxTime bit位数为{TimeBits}时表示的最大相对时间戳
+func (l Layout) MaxTime() int64 {
+	return -1 ^ (-1 << l.TimeBits)
+}
+
+// epochUnits 以{TimeUnit}为单位的Epoch
+func (l Layout) epochUnits() int64 {
+	return l.Epoch.UnixNano() / int64(l.TimeUnit)
+}
+
+// Extract 根据ID提取head, 当时时间戳(毫秒), 节点ID, 序列号
+func (l Layout) Extract(id ID) (int64, int64, int64, int64) {
+	var i = int64(id)
+
+	var head = i >> (l.TimeBits + l.NodeBits + l.SequenceBits)
+	var relative = (i >> (l.NodeBits + l.SequenceBits)) & l.MaxTime()
+	var node = (i >> l.SequenceBits) & l.MaxNodeID()
+	var sequence = i & l.MaxSequence()
+
+	var timestamp = (l.epochUnits() + relative) * int64(l.TimeUnit) / int64(time.Millisecond)
+
+	return head, timestamp, node, sequence
+}
@@ -0,0 +1,167 @@
+package snowflakev1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNoFreeNode 没有空闲的节点ID
+var ErrNoFreeNode = errors.New("no free node id")
+
+// ErrNodeHalted 节点已停止生成ID (租约丢失或已关闭)
+var ErrNodeHalted = errors.New("node halted")
+
+// Lease 节点ID租约
+type Lease interface {
+	// NodeID 占用的节点ID
+	NodeID() int64
+
+	// Lost 租约丢失(过期/续约失败/释放)时关闭, 须在释放节点ID之前关闭
+	Lost() <-chan struct{}
+
+	// Close 停止续约并释放节点ID
+	Close() error
+}
+
+// NodeLeaser 节点ID分配器, 保证同一时刻每个节点ID最多被一个租约占用
+type NodeLeaser interface {
+	// Acquire 占用一个 [0, maxNodeID] 范围内空闲的节点ID并保持租约, 没有空闲ID时返回 ErrNoFreeNode
+	Acquire(ctx context.Context, maxNodeID int64) (Lease, error)
+}
+
+// NewLeasedNode 通过 {leaser} 占用节点ID并初始化节点
+// 租约丢失时节点立即停止生成ID并返回 ErrNodeHalted; 调用 Close 释放节点ID
+func NewLeasedNode(ctx context.Context, leaser NodeLeaser, layout Layout) (*Node, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	var lease, err = leaser.Acquire(ctx, layout.MaxNodeID())
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := NewNodeWithLayout(lease.NodeID(), layout)
+	if err != nil {
+		var _ = lease.Close()
+		return nil, err
+	}
+
+	node.lease = lease
+	return node, nil
+}
+
+// halt 停止生成ID
+func (n *Node) halt() {
+	atomic.StoreInt32(&n.halted, 1)
+}
+
+// Halted 节点是否已停止生成ID
+// 每次生成ID前检查租约, 租约丢失后 (释放节点ID前) 不会再生成ID
+func (n *Node) Halted() bool {
+	if atomic.LoadInt32(&n.halted) != 0 {
+		return true
+	}
+
+	if n.lease != nil {
+		select {
+		case <-n.lease.Lost():
+			n.halt()
+			return true
+		default:
+		}
+	}
+	return false
+}
+
+// Close 停止生成ID, 若节点ID来自租约则释放
+func (n *Node) Close() error {
+	n.halt()
+
+	if n.lease != nil {
+		return n.lease.Close()
+	}
+	return nil
+}
+
+// MemoryLeaser 内存节点ID分配器, 用于测试或单进程多节点, 并发安全
+type MemoryLeaser struct {
+	mux sync.Mutex
+
+	leases map[int64]*memoryLease
+}
+
+type memoryLease struct {
+	leaser *MemoryLeaser
+	nodeID int64
+
+	once sync.Once
+	lost chan struct{}
+}
+
+// NewMemoryLeaser 初始化
+func NewMemoryLeaser() *MemoryLeaser {
+	return &MemoryLeaser{
+		leases: make(map[int64]*memoryLease),
+	}
+}
+
+// Acquire 占用最小的空闲节点ID
+func (l *MemoryLeaser) Acquire(ctx context.Context, maxNodeID int64) (Lease, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for id := int64(0); id <= maxNodeID; id++ {
+		if _, ok := l.leases[id]; ok {
+			continue
+		}
+
+		var lease = &memoryLease{
+			leaser: l,
+			nodeID: id,
+			lost:   make(chan struct{}),
+		}
+		l.leases[id] = lease
+		return lease, nil
+	}
+
+	return nil, ErrNoFreeNode
+}
+
+// Expire 使 {nodeID} 的租约立即失效, 模拟续约失败
+func (l *MemoryLeaser) Expire(nodeID int64) error {
+	l.mux.Lock()
+	var lease, ok = l.leases[nodeID]
+	l.mux.Unlock()
+
+	if !ok {
+		return fmt.Errorf("node id %d not leased", nodeID)
+	}
+	return lease.Close()
+}
+
+func (l *memoryLease) NodeID() int64 {
+	return l.nodeID
+}
+
+func (l *memoryLease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+func (l *memoryLease) Close() error {
+	l.once.Do(func() {
+		// 先通知节点停止生成, 再释放节点ID
+		close(l.lost)
+
+		l.leaser.mux.Lock()
+		defer l.leaser.mux.Unlock()
+
+		if l.leaser.leases[l.nodeID] == l {
+			delete(l.leaser.leases, l.nodeID)
+		}
+	})
+	return nil
+}
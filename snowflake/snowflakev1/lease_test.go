@@ -0,0 +1,64 @@
+package snowflakev1_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alpha-abc/gokits/snowflake/snowflakev1"
+)
+
+func TestLeasedNode(t *testing.T) {
+	var layout = snowflakev1.DefaultLayout()
+	layout.NodeBits = 1 // 只有 0, 1 两个节点ID
+
+	var leaser = snowflakev1.NewMemoryLeaser()
+	var ctx = context.Background()
+
+	n1, err := snowflakev1.NewLeasedNode(ctx, leaser, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n2, err := snowflakev1.NewLeasedNode(ctx, leaser, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := snowflakev1.NewLeasedNode(ctx, leaser, layout); !errors.Is(err, snowflakev1.ErrNoFreeNode) {
+		t.Fatalf("expect no free node, got %v", err)
+	}
+
+	var id1, _ = n1.Generate()
+	var id2, _ = n2.Generate()
+	var _, _, node1, _ = n1.Extract(id1)
+	var _, _, node2, _ = n2.Extract(id2)
+	if node1 == node2 {
+		t.Fatalf("two live nodes share node id %d", node1)
+	}
+
+	// 租约丢失后停止生成
+	if err := leaser.Expire(node1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n1.Generate(); !errors.Is(err, snowflakev1.ErrNodeHalted) {
+		t.Fatalf("expect halted, got %v", err)
+	}
+
+	// 关闭后释放节点ID, 可被再次占用
+	if err := n2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n2.Generate(); !errors.Is(err, snowflakev1.ErrNodeHalted) {
+		t.Fatalf("expect halted after close, got %v", err)
+	}
+
+	n3, err := snowflakev1.NewLeasedNode(ctx, leaser, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { var _ = n3.Close() }()
+
+	if _, err := n3.Generate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,10 +2,18 @@ package snowflakev1
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
+// CreateNodeId 通过 {key} 的版本号生成节点ID
+//
+// Deprecated: 版本号回绕后, 两个存活的进程可能得到相同的节点ID, 且节点ID不会被释放, 请使用 EtcdLeaser
 func CreateNodeId(cli *clientv3.Client, key string, limit int64) (int64, error) {
 	var resp, err = cli.Put(context.TODO(), key, "", clientv3.WithPrevKV())
 	if err != nil {
@@ -24,3 +32,162 @@ func CreateNodeId(cli *clientv3.Client, key string, limit int64) (int64, error)
 
 	return resp.PrevKv.Version + 1, nil
 }
+
+// EtcdLeaser 基于 etcd 租约的节点ID分配器
+// 节点ID {id} 对应的 key 为 {prefix}/{id}, 通过事务保证只有 key 不存在时才能占用,
+// key 绑定租约, 进程退出或续约失败后随租约过期自动删除
+type EtcdLeaser struct {
+	cli    *clientv3.Client
+	prefix string
+	ttl    time.Duration
+	value  string
+}
+
+// NewEtcdLeaser 初始化, ttl 为租约时长(最小1秒)
+func NewEtcdLeaser(cli *clientv3.Client, prefix string, ttl time.Duration) *EtcdLeaser {
+	var host, _ = os.Hostname()
+
+	return &EtcdLeaser{
+		cli:    cli,
+		prefix: prefix,
+		ttl:    ttl,
+		value:  fmt.Sprintf("%s:%d", host, os.Getpid()),
+	}
+}
+
+// Acquire 从随机位置开始依次尝试占用空闲的节点ID
+func (l *EtcdLeaser) Acquire(ctx context.Context, maxNodeID int64) (Lease, error) {
+	var ttl = int64(l.ttl / time.Second)
+	if ttl < 1 {
+		ttl = 1
+	}
+
+	var grant, err = l.cli.Grant(ctx, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	var total = maxNodeID + 1
+	var offset = rand.Int63n(total)
+
+	for i := int64(0); i < total; i++ {
+		var id = (offset + i) % total
+		var key = fmt.Sprintf("%s/%d", l.prefix, id)
+
+		var resp, err = l.cli.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+			Then(clientv3.OpPut(key, l.value, clientv3.WithLease(grant.ID))).
+			Commit()
+		if err != nil {
+			var _ = l.revoke(grant.ID)
+			return nil, err
+		}
+
+		if resp.Succeeded {
+			return l.keepAlive(id, grant)
+		}
+	}
+
+	var _ = l.revoke(grant.ID)
+	return nil, ErrNoFreeNode
+}
+
+// keepAlive 保持租约, 续约失败或超过租约时长未续约成功时视为租约丢失
+func (l *EtcdLeaser) keepAlive(id int64, grant *clientv3.LeaseGrantResponse) (Lease, error) {
+	var ctx, cancel = context.WithCancel(context.Background())
+
+	var ch, err = l.cli.KeepAlive(ctx, grant.ID)
+	if err != nil {
+		cancel()
+		var _ = l.revoke(grant.ID)
+		return nil, err
+	}
+
+	var lease = &etcdLease{
+		leaser:  l,
+		nodeID:  id,
+		leaseID: grant.ID,
+		cancel:  cancel,
+		lost:    make(chan struct{}),
+	}
+
+	go lease.watch(ch, time.Duration(grant.TTL)*time.Second)
+	return lease, nil
+}
+
+// revoke 撤销租约, 最多等待一个租约时长(最少1秒)
+func (l *EtcdLeaser) revoke(id clientv3.LeaseID) error {
+	var timeout = l.ttl
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+
+	var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var _, err = l.cli.Revoke(ctx, id)
+	return err
+}
+
+type etcdLease struct {
+	leaser  *EtcdLeaser
+	nodeID  int64
+	leaseID clientv3.LeaseID
+	cancel  context.CancelFunc
+
+	lostOnce  sync.Once
+	lost      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// watch 监听续约结果, 留出 1/3 租约时长的余量, 保证在 etcd 删除 key 之前停止生成ID
+func (l *etcdLease) watch(ch <-chan *clientv3.LeaseKeepAliveResponse, ttl time.Duration) {
+	var deadline = ttl * 2 / 3
+	var timer = time.NewTimer(deadline)
+	defer timer.Stop()
+
+	for {
+		select {
+		case resp, ok := <-ch:
+			if !ok || resp == nil {
+				l.markLost()
+				return
+			}
+
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(time.Duration(resp.TTL) * time.Second * 2 / 3)
+		case <-timer.C:
+			l.markLost()
+			return
+		case <-l.lost:
+			return
+		}
+	}
+}
+
+func (l *etcdLease) markLost() {
+	l.lostOnce.Do(func() {
+		close(l.lost)
+	})
+}
+
+func (l *etcdLease) NodeID() int64 {
+	return l.nodeID
+}
+
+func (l *etcdLease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Close 停止续约并撤销租约, etcd 随之删除节点ID对应的 key
+func (l *etcdLease) Close() error {
+	l.closeOnce.Do(func() {
+		l.markLost()
+		l.cancel()
+		l.closeErr = l.leaser.revoke(l.leaseID)
+	})
+	return l.closeErr
+}
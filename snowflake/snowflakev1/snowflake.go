@@ -2,13 +2,15 @@ package snowflakev1
 
 import (
 	"fmt"
-	"sync"
+	"sync/atomic"
 	"time"
 )
 
 /*
-[head: 1bit]   [         timestamp millisecond: 41bit            ]   [node:10bit]   [ seq: 12bit ]
-    0    -     00000000  00000000  00000000  00000000  00000000  0 - 00000000  00 - 00000000  0000
+默认布局:
+[head: 1bit]   [         timestamp millisecond: 41bit            ]   [node:10bit]   [ seq: 10bit ]
+    0    -     00000000  00000000  00000000  00000000  00000000  0 - 00000000  00 - 00000000  00
+其余1bit未使用, 可通过 Layout 自定义
 */
 
 var (
@@ -31,53 +33,98 @@ var (
 // ID snowflake id
 type ID int64
 
-// Node 生成ID的基本节点
+// Node 生成ID的基本节点, 并发安全
+// 上次生成ID的时间戳与序列号打包在一个 int64 中, 通过 CAS 无锁更新
 type Node struct {
-	mux sync.Mutex
+	// state 打包的 [相对时间戳 | 序列号], 需放在首位保证 32 位平台上原子操作的对齐
+	state int64
 
-	epoch          int64
-	headBitLen     uint8
-	timeBitLen     uint8
-	nodeBitLen     uint8
-	sequenceBitLen uint8
+	layout     Layout
+	epochUnits int64 // 以 layout.TimeUnit 为单位的 Epoch
 
-	relativeTimestamp int64
-	nodeID            int64
-	sequence          int64
+	clock       Clock
+	rollback    RollbackStrategy
+	maxRollback time.Duration // 可容忍的最大回拨时长, 0 表示不限制 (仅 RollbackLogical)
+
+	nodeID int64
+
+	halted int32 // 非0时停止生成ID
+	lease  Lease // 节点ID租约, 可选
 }
 
+// SetNodeID 设置节点ID, 需在生成ID之前调用
 func (n *Node) SetNodeID(nodeID int64) {
 	n.nodeID = nodeID
 }
 
-func currMillisecond() int64 {
-	return time.Now().UnixNano() / 1e6
+// SetClock 设置时钟, 并以新时钟的当前时间重置节点时间, 需在生成ID之前调用
+func (n *Node) SetClock(clock Clock) {
+	n.clock = clock
+	atomic.StoreInt64(&n.state, n.pack(n.now()-n.epochUnits, 0))
+}
+
+// SetRollback 设置时钟回拨策略, 需在生成ID之前调用
+// RollbackWait: {max} 为最长等待时间, 回拨超过 {max} 时直接返回错误
+// RollbackLogical: {max} 为逻辑时间允许领先时钟的最大时长, 0 表示不限制
+func (n *Node) SetRollback(strategy RollbackStrategy, max time.Duration) {
+	n.rollback = strategy
+	n.maxRollback = max
+}
+
+// now 以 layout.TimeUnit 为单位的当前时间戳
+func (n *Node) now() int64 {
+	return n.clock.Now().UnixNano() / int64(n.layout.TimeUnit)
 }
 
-// NewNode 初始化节点
+// pack 打包相对时间戳与序列号
+func (n *Node) pack(relative int64, sequence int64) int64 {
+	return relative<<n.layout.SequenceBits | sequence
+}
+
+// unpack 解包相对时间戳与序列号
+func (n *Node) unpack(state int64) (int64, int64) {
+	return state >> n.layout.SequenceBits, state & n.layout.MaxSequence()
+}
+
+// NewNode 使用默认布局初始化节点
 func NewNode(nodeID int64) (*Node, error) {
-	var maxNodeID = -1 ^ (-1 << nodeBitLen) // bit位数为{nodeBitLen}时表示的最大数
-	if nodeID < 0 || nodeID > int64(maxNodeID) {
+	return NewNodeWithLayout(nodeID, DefaultLayout())
+}
+
+// NewNodeWithLayout 使用自定义布局初始化节点
+func NewNodeWithLayout(nodeID int64, layout Layout) (*Node, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+
+	var maxNodeID = layout.MaxNodeID()
+	if nodeID < 0 || nodeID > maxNodeID {
 		return nil, fmt.Errorf("invalid noedeID, require [%d - %d]", 0, maxNodeID)
 	}
 
-	var now = currMillisecond()
-	if now < Epoch {
-		return nil, fmt.Errorf("epoch: %d greater than current millisecond: %d", Epoch, now)
+	var epochUnits = layout.epochUnits()
+	var now = SystemClock.Now().UnixNano() / int64(layout.TimeUnit)
+	if now < epochUnits {
+		return nil, fmt.Errorf("epoch: %d greater than current time: %d", epochUnits, now)
 	}
 
-	return &Node{
-		epoch:          Epoch,
-		headBitLen:     headBitLen,
-		timeBitLen:     timeBitLen,
-		nodeBitLen:     nodeBitLen,
-		sequenceBitLen: sequenceBitLen,
+	var n = &Node{
+		layout:     layout,
+		epochUnits: epochUnits,
+
+		clock:    SystemClock,
+		rollback: RollbackFail,
 
-		relativeTimestamp: now - Epoch,
-		nodeID:            nodeID,
-		sequence:          0,
-	}, nil
+		nodeID: nodeID,
+	}
+	n.state = n.pack(now-epochUnits, 0)
+
+	return n, nil
+}
 
+// Layout 返回节点的比特位布局
+func (n *Node) Layout() Layout {
+	return n.layout
 }
 
 // Generate 返回一个唯一的ID
@@ -85,46 +132,105 @@ func NewNode(nodeID int64) (*Node, error) {
 // - 保证系统时间的精确性
 // - 保证任意两个Node不能有相同的节点ID
 func (n *Node) Generate() (ID, error) {
-	n.mux.Lock()
-	defer n.mux.Unlock()
-
-	var now = currMillisecond() // 当前毫秒时间戳
-	var nodeTime = n.relativeTimestamp + n.epoch
+	return n.GenerateN(1)
+}
 
-	if now < nodeTime {
-		return 0, fmt.Errorf("clock(%d) move backwards, reject generate until %d", now, nodeTime)
+// GenerateN 预留 {count} 个连续的ID, 返回第一个ID, 即预留 [id, id+count)
+// {count} 不能超过一个时间单位内的序列号数量 (layout.MaxSequence()+1);
+// 当前时间单位剩余的序列号不足时, 等待至下一时间单位 (RollbackLogical 时借用下一时间单位)
+func (n *Node) GenerateN(count int) (ID, error) {
+	var maxSequence = n.layout.MaxSequence()
+	if count <= 0 || int64(count) > maxSequence+1 {
+		return 0, fmt.Errorf("invalid count %d, require [1 - %d]", count, maxSequence+1)
 	}
 
-	if now == nodeTime {
-		n.sequence = (n.sequence + 1) & (-1 ^ (-1 << n.sequenceBitLen))
+	for {
+		if n.Halted() {
+			return 0, ErrNodeHalted
+		}
 
-		if n.sequence == 0 {
-			// 等待下一毫秒
-			for now <= nodeTime {
-				now = currMillisecond()
+		var old = atomic.LoadInt64(&n.state)
+		var last, sequence = n.unpack(old)
+
+		var now = n.now() - n.epochUnits // 当前相对时间戳
+		if now < last {
+			var t, err = n.tolerate(now+n.epochUnits, last+n.epochUnits)
+			if err != nil {
+				return 0, err
 			}
+			now = t - n.epochUnits
 		}
-	} else {
-		n.sequence = 0
-	}
 
-	n.relativeTimestamp = now - n.epoch
+		var start int64 = 0
+		if now == last {
+			start = sequence + 1
 
-	var resp = (n.relativeTimestamp << (n.nodeBitLen + n.sequenceBitLen)) |
-		(n.nodeID << n.sequenceBitLen) |
-		n.sequence
+			if start+int64(count)-1 > maxSequence {
+				if n.rollback != RollbackLogical {
+					// 等待下一时间单位
+					n.waitNext(last)
+					continue
+				}
 
-	return ID(resp), nil
+				// 借用下一时间单位
+				now, start = last+1, 0
+			}
+		}
+
+		if now > n.layout.MaxTime() {
+			return 0, fmt.Errorf("time(%d) overflow, max relative time %d", now, n.layout.MaxTime())
+		}
+
+		if !atomic.CompareAndSwapInt64(&n.state, old, n.pack(now, start+int64(count)-1)) {
+			continue
+		}
+
+		var resp = (now << (n.layout.NodeBits + n.layout.SequenceBits)) |
+			(n.nodeID << n.layout.SequenceBits) |
+			start
+
+		return ID(resp), nil
+	}
 }
 
-// Extract 根据ID提取head,当时时间戳, 节点ID, 序列号
-func Extract(id ID) (int64, int64, int64, int64) {
-	var i = int64(id)
+// waitNext 休眠至相对时间戳 {last} 的下一时间单位开始
+func (n *Node) waitNext(last int64) {
+	var next = time.Duration(last+1+n.epochUnits) * n.layout.TimeUnit
+	var d = next - time.Duration(n.clock.Now().UnixNano())
+	if d > 0 {
+		n.clock.Sleep(d)
+	}
+}
 
-	var head = i >> (timeBitLen + nodeBitLen + sequenceBitLen)
-	var timestamp = (i >> (nodeBitLen + sequenceBitLen)) - ((i >> (timeBitLen + nodeBitLen + sequenceBitLen)) << timeBitLen) + Epoch
-	var node = (i >> sequenceBitLen) - ((i >> (nodeBitLen + sequenceBitLen)) << nodeBitLen)
-	var sequence = i - ((i >> sequenceBitLen) << sequenceBitLen)
+// tolerate 按回拨策略处理 now < nodeTime 的情况, 返回可使用的时间戳
+func (n *Node) tolerate(now int64, nodeTime int64) (int64, error) {
+	var back = time.Duration(nodeTime-now) * n.layout.TimeUnit
 
-	return head, timestamp, node, sequence
+	switch n.rollback {
+	case RollbackWait:
+		if back > n.maxRollback {
+			break
+		}
+
+		n.clock.Sleep(back)
+		if now = n.now(); now >= nodeTime {
+			return now, nil
+		}
+	case RollbackLogical:
+		if n.maxRollback == 0 || back <= n.maxRollback {
+			return nodeTime, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: clock(%d) behind %d by %s, strategy %s", ErrClockBackwards, now, nodeTime, back, n.rollback)
+}
+
+// Extract 根据节点的布局从ID提取head, 当时时间戳(毫秒), 节点ID, 序列号
+func (n *Node) Extract(id ID) (int64, int64, int64, int64) {
+	return n.layout.Extract(id)
+}
+
+// Extract 根据默认布局从ID提取head, 当时时间戳(毫秒), 节点ID, 序列号
+func Extract(id ID) (int64, int64, int64, int64) {
+	return DefaultLayout().Extract(id)
 }
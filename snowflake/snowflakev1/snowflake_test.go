@@ -55,3 +55,121 @@ func Test_NodeID(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestLayout(t *testing.T) {
+	var layout = snowflakev1.Layout{
+		Epoch:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		TimeUnit:     10 * time.Millisecond,
+		TimeBits:     39,
+		NodeBits:     8,
+		SequenceBits: 16,
+	}
+
+	var node, err = snowflakev1.NewNodeWithLayout(255, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var before = time.Now().UnixNano() / 1e6
+	id, err := node.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var head, ts, nodeID, seq = node.Extract(id)
+	if head != 0 || nodeID != 255 || seq > 1 {
+		t.Fatalf("unexpected extract: %d %d %d %d", head, ts, nodeID, seq)
+	}
+	if ts > before || before-ts > 20 {
+		t.Fatalf("unexpected timestamp %d, now %d", ts, before)
+	}
+
+	if _, err := snowflakev1.NewNodeWithLayout(256, layout); err == nil {
+		t.Fatal("expect invalid node id error")
+	}
+
+	layout.TimeBits = 40
+	if _, err := snowflakev1.NewNodeWithLayout(1, layout); err == nil {
+		t.Fatal("expect layout overflow error")
+	}
+
+	// 非法的起始时间
+	layout.TimeBits = 39
+	for _, epoch := range []time.Time{
+		{},
+		time.Now().Add(time.Hour),
+		time.Date(1600, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		layout.Epoch = epoch
+		if err := layout.Validate(); err == nil {
+			t.Fatalf("expect invalid epoch error for %s", epoch)
+		}
+	}
+}
+
+func TestDefaultLayoutExtract(t *testing.T) {
+	var node, _ = snowflakev1.NewNode(3)
+	var id, _ = node.Generate()
+
+	var h1, t1, n1, s1 = snowflakev1.Extract(id)
+	var h2, t2, n2, s2 = node.Extract(id)
+	if h1 != h2 || t1 != t2 || n1 != n2 || s1 != s2 || n1 != 3 {
+		t.Fatalf("extract mismatch: %d %d %d %d / %d %d %d %d", h1, t1, n1, s1, h2, t2, n2, s2)
+	}
+}
+
+func TestGenerateConcurrent(t *testing.T) {
+	var node, err = snowflakev1.NewNode(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const workers, each = 8, 2000
+	var ids = make(chan snowflakev1.ID, workers*each)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(batch bool) {
+			defer wg.Done()
+
+			for j := 0; j < each; {
+				if batch {
+					var start, err = node.GenerateN(10)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					for k := 0; k < 10; k++ {
+						ids <- start + snowflakev1.ID(k)
+					}
+					j += 10
+					continue
+				}
+
+				var id, err = node.Generate()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids <- id
+				j++
+			}
+		}(i%2 == 0)
+	}
+
+	wg.Wait()
+	close(ids)
+
+	var seen = make(map[snowflakev1.ID]struct{}, workers*each)
+	for id := range ids {
+		if _, ok := seen[id]; ok {
+			t.Fatalf("duplicate id %d", id)
+		}
+		seen[id] = struct{}{}
+	}
+	if len(seen) != workers*each {
+		t.Fatalf("expect %d ids, got %d", workers*each, len(seen))
+	}
+}
@@ -0,0 +1,129 @@
+package snowflakev1
+
+import (
+	"fmt"
+	"time"
+)
+
+// ULID 128位可排序ID: [毫秒时间戳: 48bit] [随机数: 80bit]
+// 字符串为26位 Crockford base32, 按字典序与生成顺序一致, 见 https://github.com/ulid/spec
+type ULID [16]byte
+
+// ulidLen ULID 字符串长度
+const ulidLen = 26
+
+// ULIDGenerator ULID 生成器, 同一毫秒内单调递增, 并发安全
+type ULIDGenerator struct {
+	monotonic
+}
+
+// NewULIDGenerator 初始化, 默认使用系统时钟, crypto/rand 及 RollbackFail
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{
+		monotonic: newMonotonic(16, 64),
+	}
+}
+
+// Generate 生成一个 ULID
+func (g *ULIDGenerator) Generate() (ULID, error) {
+	var ms, hi, lo, err = g.next()
+	if err != nil {
+		return ULID{}, err
+	}
+
+	var u ULID
+	putTimestamp(u[:6], ms)
+	u[6] = byte(hi >> 8)
+	u[7] = byte(hi)
+	for i := 0; i < 8; i++ {
+		u[8+i] = byte(lo >> (56 - 8*i))
+	}
+	return u, nil
+}
+
+// NextID 生成一个 ULID, 返回其字符串形式
+func (g *ULIDGenerator) NextID() (string, error) {
+	var u, err = g.Generate()
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Time 解析 ULID 字符串, 返回生成时间
+func (g *ULIDGenerator) Time(id string) (time.Time, error) {
+	var u, err = ParseULID(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return u.Time(), nil
+}
+
+// Timestamp 毫秒时间戳
+func (u ULID) Timestamp() int64 {
+	return timestamp(u[:6])
+}
+
+// Time 生成时间
+func (u ULID) Time() time.Time {
+	return time.Unix(0, u.Timestamp()*int64(time.Millisecond))
+}
+
+// String 26位 Crockford base32 字符串 (大写)
+// 128位前补2个0比特, 每5比特一个字符
+func (u ULID) String() string {
+	var s [ulidLen]byte
+	for i := range s {
+		var v byte
+		for j := 0; j < 5; j++ {
+			var bit = i*5 + j - 2
+			v <<= 1
+			if bit >= 0 && u[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		s[i] = base32Alphabet[v]
+	}
+	return string(s[:])
+}
+
+// ParseULID 解析 ULID 字符串, 不区分大小写; 长度不为26, 含非法字符或超出128位时返回 ErrInvalidID
+func ParseULID(s string) (ULID, error) {
+	var u ULID
+	if len(s) != ulidLen {
+		return u, fmt.Errorf("%w: ulid %q length %d, require %d", ErrInvalidID, s, len(s), ulidLen)
+	}
+
+	for i := 0; i < ulidLen; i++ {
+		var v = base32Decode[s[i]]
+		if v == 0xFF {
+			return u, fmt.Errorf("%w: ulid %q has invalid char %q", ErrInvalidID, s, s[i])
+		}
+		if i == 0 && v > 7 {
+			return u, fmt.Errorf("%w: ulid %q overflow", ErrInvalidID, s)
+		}
+
+		for j := 0; j < 5; j++ {
+			var bit = i*5 + j - 2
+			if bit >= 0 && v&(0x10>>j) != 0 {
+				u[bit/8] |= 0x80 >> (bit % 8)
+			}
+		}
+	}
+	return u, nil
+}
+
+// MarshalText 序列化为字符串
+func (u ULID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText 解析字符串
+func (u *ULID) UnmarshalText(b []byte) error {
+	var v, err = ParseULID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
@@ -0,0 +1,134 @@
+package snowflakev1
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// UUID RFC 9562 UUIDv7: [毫秒时间戳: 48bit] [版本7: 4bit] [rand_a: 12bit] [变体10: 2bit] [rand_b: 62bit]
+// 字符串为小写 8-4-4-4-12 格式, 按字典序与生成顺序一致
+type UUID [16]byte
+
+// UUIDGenerator UUIDv7 生成器, rand_a 与 rand_b 作为一个74位的数在同一毫秒内单调递增, 并发安全
+type UUIDGenerator struct {
+	monotonic
+}
+
+// NewUUIDGenerator 初始化, 默认使用系统时钟, crypto/rand 及 RollbackFail
+func NewUUIDGenerator() *UUIDGenerator {
+	return &UUIDGenerator{
+		monotonic: newMonotonic(12, 62),
+	}
+}
+
+// Generate 生成一个 UUIDv7
+func (g *UUIDGenerator) Generate() (UUID, error) {
+	var ms, hi, lo, err = g.next()
+	if err != nil {
+		return UUID{}, err
+	}
+
+	var u UUID
+	putTimestamp(u[:6], ms)
+	u[6] = 0x70 | byte(hi>>8)
+	u[7] = byte(hi)
+	u[8] = 0x80 | byte(lo>>56)
+	for i := 1; i < 8; i++ {
+		u[8+i] = byte(lo >> (56 - 8*i))
+	}
+	return u, nil
+}
+
+// NextID 生成一个 UUIDv7, 返回其字符串形式
+func (g *UUIDGenerator) NextID() (string, error) {
+	var u, err = g.Generate()
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Time 解析 UUIDv7 字符串, 返回生成时间
+func (g *UUIDGenerator) Time(id string) (time.Time, error) {
+	var u, err = ParseUUID(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return u.Time(), nil
+}
+
+// Version 版本号
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Timestamp 毫秒时间戳
+func (u UUID) Timestamp() int64 {
+	return timestamp(u[:6])
+}
+
+// Time 生成时间
+func (u UUID) Time() time.Time {
+	return time.Unix(0, u.Timestamp()*int64(time.Millisecond))
+}
+
+// String 小写 8-4-4-4-12 格式
+func (u UUID) String() string {
+	var s [36]byte
+	hex.Encode(s[0:8], u[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], u[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], u[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], u[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], u[10:])
+	return string(s[:])
+}
+
+// ParseUUID 解析 8-4-4-4-12 格式或32位十六进制的 UUIDv7, 不区分大小写
+// 格式错误, 版本不为7或变体不为 RFC 9562 时返回 ErrInvalidID
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+
+	var h = s
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, fmt.Errorf("%w: uuid %q invalid format", ErrInvalidID, s)
+		}
+		h = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return u, fmt.Errorf("%w: uuid %q length %d, require 36 or 32", ErrInvalidID, s, len(s))
+	}
+
+	if _, err := hex.Decode(u[:], []byte(h)); err != nil {
+		return u, fmt.Errorf("%w: uuid %q: %v", ErrInvalidID, s, err)
+	}
+
+	if u.Version() != 7 {
+		return u, fmt.Errorf("%w: uuid %q version %d, require 7", ErrInvalidID, s, u.Version())
+	}
+	if u[8]&0xC0 != 0x80 {
+		return u, fmt.Errorf("%w: uuid %q invalid variant", ErrInvalidID, s)
+	}
+	return u, nil
+}
+
+// MarshalText 序列化为字符串
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText 解析字符串
+func (u *UUID) UnmarshalText(b []byte) error {
+	var v, err = ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}